@@ -0,0 +1,327 @@
+// Package censor finds words from dictionary in messages, text is normalized to NFKC and
+// look-alike letters, transliteration, spaced and repeated letters are folded before matching
+package censor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// minMergedLength is a minimal length of word merged from single letters like "х.у.й"
+const minMergedLength = 3
+
+// homoglyphs are Latin, Greek, digits and symbols looking like Cyrillic letters,
+// also Cyrillic letters often replaced with similar ones
+var homoglyphs = map[rune]rune{
+	'a': 'а', 'b': 'в', 'c': 'с', 'e': 'е', 'h': 'н', 'k': 'к', 'm': 'м', 'n': 'п',
+	'o': 'о', 'p': 'р', 'r': 'г', 't': 'т', 'u': 'и', 'x': 'х', 'y': 'у',
+	'α': 'а', 'β': 'в', 'ε': 'е', 'η': 'п', 'κ': 'к', 'ο': 'о', 'π': 'п', 'ρ': 'р', 'τ': 'т', 'υ': 'у', 'χ': 'х',
+	'0': 'о', '3': 'з', '4': 'ч', '6': 'б', '@': 'а', '$': 'с',
+	'ё': 'е', 'й': 'и',
+}
+
+// transliteration of Latin words to Cyrillic, multi-letter sequences go first
+var transliteration = strings.NewReplacer(
+	"shch", "щ", "sch", "щ", "zh", "ж", "kh", "х", "ts", "ц", "ch", "ч", "sh", "ш", "ya", "я", "yu", "ю", "yo", "е",
+	"a", "а", "b", "б", "v", "в", "g", "г", "d", "д", "e", "е", "z", "з", "i", "и", "j", "й", "k", "к", "l", "л",
+	"m", "м", "n", "н", "o", "о", "p", "п", "r", "р", "s", "с", "t", "т", "u", "у", "f", "ф", "h", "х", "c", "ц",
+	"y", "ы", "w", "в", "x", "кс", "q", "к",
+)
+
+// Match is a dictionary entry found in text
+type Match struct {
+	Word  string // normalized word of text
+	Entry string // dictionary entry
+}
+
+// pattern is a glob with * and ? wildcards
+type pattern struct {
+	entry string
+	runes []rune
+}
+
+type regexpEntry struct {
+	entry  string
+	regexp *regexp.Regexp
+}
+
+// Filter is a compiled dictionary, it is safe for concurrent use.
+// Dictionary entries are: word for exact match, glob with * and ? wildcards (e.g. stem*),
+// /regexp/ applied to normalized text and !word or !glob for allowed words
+type Filter struct {
+	words        map[string]string // normalized word -> entry
+	globs        []pattern
+	regexps      []regexpEntry
+	allowedWords map[string]bool
+	allowedGlobs []pattern
+}
+
+// New compiles dictionary entries to filter
+func New(entries []string) (f *Filter, err error) {
+	f = &Filter{
+		words:        make(map[string]string),
+		allowedWords: make(map[string]bool),
+	}
+	for _, entry := range entries {
+		if err = f.add(strings.TrimSpace(entry)); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+func (f *Filter) add(entry string) error {
+	if entry == "" {
+		return nil
+	}
+
+	if len(entry) > 2 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/") {
+		re, err := regexp.Compile(entry[1 : len(entry)-1])
+		if err != nil {
+			return fmt.Errorf("invalid regexp entry %s: %s", entry, err)
+		}
+		f.regexps = append(f.regexps, regexpEntry{entry: entry, regexp: re})
+		return nil
+	}
+
+	allowed := strings.HasPrefix(entry, "!")
+	word := normalizeWord(norm.NFKC.String(strings.TrimPrefix(entry, "!")))
+	if word == "" {
+		return fmt.Errorf("invalid entry %s", entry)
+	}
+	isGlob := strings.ContainsAny(word, "*?")
+	switch {
+	case allowed && isGlob:
+		f.allowedGlobs = append(f.allowedGlobs, pattern{entry: entry, runes: []rune(word)})
+	case allowed:
+		f.allowedWords[word] = true
+	case isGlob:
+		f.globs = append(f.globs, pattern{entry: entry, runes: []rune(word)})
+	default:
+		f.words[word] = entry
+	}
+	return nil
+}
+
+// Match returns the first dictionary entry found in text
+func (f *Filter) Match(text string) (match Match, ok bool) {
+	var words []string
+	for _, token := range tokenize(text) {
+		variants := getVariants(token)
+		if f.isAllowed(variants) {
+			continue
+		}
+		for _, variant := range variants {
+			if match, ok = f.matchWord(variant); ok {
+				return
+			}
+		}
+		words = append(words, variants[0])
+	}
+
+	normalized := strings.Join(words, " ")
+	for _, re := range f.regexps {
+		if found := re.regexp.FindString(normalized); found != "" {
+			return Match{Word: found, Entry: re.entry}, true
+		}
+	}
+	return Match{}, false
+}
+
+func (f *Filter) matchWord(word string) (match Match, ok bool) {
+	if entry, found := f.words[word]; found {
+		return Match{Word: word, Entry: entry}, true
+	}
+	runes := []rune(word)
+	for _, p := range f.globs {
+		if matchGlob(p.runes, runes) {
+			return Match{Word: word, Entry: p.entry}, true
+		}
+	}
+	return Match{}, false
+}
+
+func (f *Filter) isAllowed(variants []string) bool {
+	for _, word := range variants {
+		if f.allowedWords[word] {
+			return true
+		}
+		runes := []rune(word)
+		for _, p := range f.allowedGlobs {
+			if matchGlob(p.runes, runes) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Normalize returns text in form used for matching: lower case words with folded look-alike letters
+// separated by single spaces
+func Normalize(text string) string {
+	var words []string
+	for _, token := range tokenize(text) {
+		words = append(words, getVariants(token)[0])
+	}
+	return strings.Join(words, " ")
+}
+
+// normalizeWord returns lower case word with folded look-alike letters, wildcards are kept
+func normalizeWord(word string) string {
+	return strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		if folded, ok := homoglyphs[r]; ok {
+			return folded
+		}
+		if isInvisible(r) {
+			return -1
+		}
+		return r
+	}, word)
+}
+
+// isInvisible returns true for combining marks and format characters like zero width space
+func isInvisible(r rune) bool {
+	return unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Cf, r)
+}
+
+func isWordRune(r rune) bool {
+	if _, ok := homoglyphs[unicode.ToLower(r)]; ok {
+		return true
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// tokenize splits text to lower case words, compatibility forms like fullwidth letters are replaced by NFKC,
+// invisible characters are dropped, sequences of single letters separated by spaces or punctuation are merged to one word
+func tokenize(text string) (tokens []string) {
+	var (
+		current []rune
+		singles []rune
+	)
+	flushSingles := func() {
+		if len(singles) >= minMergedLength {
+			tokens = append(tokens, string(singles))
+		} else {
+			for _, r := range singles {
+				tokens = append(tokens, string(r))
+			}
+		}
+		singles = nil
+	}
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		if !containsLetter(current) {
+			// numbers are not words
+			flushSingles()
+		} else if len(current) == 1 {
+			singles = append(singles, current[0])
+		} else {
+			flushSingles()
+			tokens = append(tokens, string(current))
+		}
+		current = nil
+	}
+
+	for _, r := range norm.NFKC.String(text) {
+		switch {
+		case isInvisible(r):
+		case isWordRune(r):
+			current = append(current, unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	flushSingles()
+	return
+}
+
+func containsLetter(runes []rune) bool {
+	for _, r := range runes {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// getVariants returns spellings of token to match: with folded look-alike letters,
+// transliterated from Latin and with collapsed repeated letters
+func getVariants(token string) (variants []string) {
+	add := func(variant string) {
+		for _, v := range variants {
+			if v == variant {
+				return
+			}
+		}
+		variants = append(variants, variant)
+	}
+
+	add(normalizeWord(token))
+	if isLatin(token) {
+		add(normalizeWord(transliteration.Replace(token)))
+	}
+	for _, variant := range variants {
+		add(collapseRepeats(variant))
+	}
+	return
+}
+
+func isLatin(token string) bool {
+	for _, r := range token {
+		if unicode.IsLetter(r) && !unicode.Is(unicode.Latin, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// collapseRepeats replaces repeated letters with one letter
+func collapseRepeats(word string) string {
+	var (
+		result []rune
+		last   rune
+	)
+	for _, r := range word {
+		if r != last {
+			result = append(result, r)
+		}
+		last = r
+	}
+	return string(result)
+}
+
+// matchGlob returns true if word matches pattern with * and ? wildcards
+func matchGlob(p, word []rune) bool {
+	var (
+		pi, wi       int
+		starP, starW = -1, 0
+	)
+	for wi < len(word) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == word[wi]):
+			pi++
+			wi++
+		case pi < len(p) && p[pi] == '*':
+			starP, starW = pi, wi
+			pi++
+		case starP != -1:
+			pi = starP + 1
+			starW++
+			wi = starW
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
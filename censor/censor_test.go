@@ -0,0 +1,289 @@
+package censor
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func mustNew(t testing.TB, entries ...string) *Filter {
+	t.Helper()
+	f, err := New(entries)
+	if err != nil {
+		t.Fatalf("New(%v): %s", entries, err)
+	}
+	return f
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"Привет, МИР!", "привет мир"},
+		{"cпaм", "спам"},          // Latin c and a
+		{"СПΑМ", "спам"},          // Greek capital alpha
+		{"сп\u200bам", "спам"},    // zero width space
+		{"спа\u0301м", "спам"},    // combining acute accent
+		{"ｂｏｔ", "вот"},            // fullwidth letters
+		{"ＣＰＡＭ", "срам"},          // fullwidth letters look like Cyrillic
+		{"с.п.а.м", "спам"},       // spaced single letters
+		{"с п а м и я", "спамия"}, // spaced single letters merged
+		{"а и", "а и"},            // too few single letters
+		{"ёжик йод", "ежик иод"},  // similar Cyrillic letters
+		{"2018 год", "год"},       // numbers are not words
+		{"c0ва", "сова"},          // digit zero in word
+		{"  много   пробелов ", "много пробелов"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.text); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestMatchFolding(t *testing.T) {
+	f := mustNew(t, "спам")
+	for _, text := range []string{
+		"это спам",
+		"это СПАМ!",
+		"это cпaм",            // homoglyphs
+		"это spam",            // transliteration
+		"это ｓｐａｍ",            // fullwidth transliteration
+		"это спаааам",         // repeated letters
+		"это с-п-а-м",         // spaced letters
+		"это сп\u00adам",      // soft hyphen
+		"это с\u200dпам",      // zero width joiner
+		"это спа\u0301м",      // combining accent
+		"это SPAAAM",          // repeated transliteration
+		"это с п а м!!!",      // spaced letters with punctuation
+		"(спам)",              // punctuation around word
+		"первая строка\nспам", // multiline text
+	} {
+		match, ok := f.Match(text)
+		if !ok {
+			t.Errorf("Match(%q) found nothing", text)
+			continue
+		}
+		if match.Entry != "спам" {
+			t.Errorf("Match(%q).Entry = %q, want спам", text, match.Entry)
+		}
+	}
+	for _, text := range []string{"", "спамер", "спа м", "это спасибо", "spa"} {
+		if match, ok := f.Match(text); ok {
+			t.Errorf("Match(%q) = %+v, want nothing", text, match)
+		}
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	f := mustNew(t, "дурак*", "?от", "*оед")
+	tests := []struct {
+		text  string
+		entry string
+		ok    bool
+	}{
+		{"дурак", "дурак*", true},
+		{"дураки", "дурак*", true},
+		{"ДУРАКОВАТЫЙ", "дурак*", true},
+		{"durak", "дурак*", true},
+		{"дура", "", false},
+		{"кот", "?от", true},
+		{"рот", "?от", true},
+		{"от", "", false},
+		{"крот", "", false},
+		{"муравьед", "", false},
+		{"людоед", "*оед", true},
+		{"оед", "*оед", true},
+	}
+	for _, tt := range tests {
+		match, ok := f.Match(tt.text)
+		if ok != tt.ok || match.Entry != tt.entry {
+			t.Errorf("Match(%q) = %+v, %v, want %q, %v", tt.text, match, ok, tt.entry, tt.ok)
+		}
+	}
+}
+
+func TestMatchRegexp(t *testing.T) {
+	f := mustNew(t, `/купи(те)? дешево/`, `/\d/`)
+	tests := []struct {
+		text string
+		word string
+		ok   bool
+	}{
+		{"Купи дешево!", "купи дешево", true},
+		{"КУПИТЕ   ДЁШЕВО", "купите дешево", true},
+		{"купи, дешево", "купи дешево", true}, // regexp is applied to normalized text
+		{"купи дорого", "", false},
+		{"купил дешево", "", false},
+		{"2018", "", false}, // numbers are dropped from normalized text
+	}
+	for _, tt := range tests {
+		match, ok := f.Match(tt.text)
+		if ok != tt.ok || match.Word != tt.word {
+			t.Errorf("Match(%q) = %+v, %v, want %q, %v", tt.text, match, ok, tt.word, tt.ok)
+		}
+	}
+}
+
+func TestMatchAllow(t *testing.T) {
+	f := mustNew(t, "спам*", "!спамер", "!спамо*", "/спам/", "!кот", "кот*")
+	tests := []struct {
+		text string
+		ok   bool
+	}{
+		{"спамить", true},
+		{"спамер", false},
+		{"СПАМЕР", false},
+		{"spamer", false},
+		{"спамооо", false}, // collapsed variant matches allowed glob
+		{"спамоботы", false},
+		{"кот", false},
+		{"котик", true},
+		{"спамер и кот", false},
+		{"спамер спамит", true},
+	}
+	for _, tt := range tests {
+		match, ok := f.Match(tt.text)
+		if ok != tt.ok {
+			t.Errorf("Match(%q) = %+v, %v, want %v", tt.text, match, ok, tt.ok)
+		}
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for _, entries := range [][]string{
+		{"/[/"},
+		{"!"},
+		{"\u200b"},
+	} {
+		if _, err := New(entries); err == nil {
+			t.Errorf("New(%q) accepted invalid entry", entries)
+		}
+	}
+	f := mustNew(t, "", "  ", "слово")
+	if _, ok := f.Match("слово"); !ok {
+		t.Error("empty entries break filter")
+	}
+}
+
+func TestMatchGlobFunc(t *testing.T) {
+	tests := []struct {
+		pattern, word string
+		ok            bool
+	}{
+		{"", "", true},
+		{"*", "", true},
+		{"*", "abc", true},
+		{"a*c", "abbbc", true},
+		{"a*c", "abcd", false},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"**b", "aab", true},
+		{"*a*b*", "xaxbx", true},
+		{"*a*b", "xbxa", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob([]rune(tt.pattern), []rune(tt.word)); got != tt.ok {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.word, got, tt.ok)
+		}
+	}
+}
+
+var (
+	benchSyllables = []string{
+		"ба", "ва", "го", "ду", "же", "зи", "ко", "ла", "му", "но", "пе", "ри", "су", "то", "фу", "хе",
+		"цо", "чу", "ша", "щи", "ры", "бл", "ст", "пр", "кр", "тр", "ол", "ан", "ер", "ук",
+	}
+	benchLatin = []string{
+		"the", "bot", "chat", "link", "free", "money", "hello", "world", "photo", "video", "github", "golang",
+	}
+)
+
+// benchWord returns random word of syllables
+func benchWord(rnd *rand.Rand, syllables int) string {
+	var word string
+	for i := 0; i < syllables; i++ {
+		word += benchSyllables[rnd.Intn(len(benchSyllables))]
+	}
+	return word
+}
+
+// benchDictionary returns dictionary of size of mat.txt: words, inflected forms as stems, a few regexps and allowed words
+func benchDictionary(rnd *rand.Rand) (entries []string) {
+	for i := 0; i < 1200; i++ {
+		entries = append(entries, benchWord(rnd, 2+rnd.Intn(3)))
+	}
+	for i := 0; i < 300; i++ {
+		entries = append(entries, benchWord(rnd, 2+rnd.Intn(2))+"*")
+	}
+	for i := 0; i < 20; i++ {
+		entries = append(entries, "*"+benchWord(rnd, 3)+"*", "?"+benchWord(rnd, 2))
+	}
+	for i := 0; i < 10; i++ {
+		entries = append(entries, fmt.Sprintf(`/\b%s\s+%s\b/`, benchWord(rnd, 2), benchWord(rnd, 2)))
+	}
+	for i := 0; i < 50; i++ {
+		entries = append(entries, "!"+benchWord(rnd, 3))
+	}
+	return
+}
+
+// benchCorpus returns chat messages from a few words to long paragraphs, Cyrillic mixed with Latin words,
+// punctuation, numbers and look-alike letters
+func benchCorpus(rnd *rand.Rand) (messages []string) {
+	natural := strings.Fields("привет как дела что нового сегодня вечером встреча в чате смотрите ссылку " +
+		"спасибо за помощь отличная идея давайте обсудим завтра кто-нибудь знает ответ")
+	for i := 0; i < 500; i++ {
+		var words []string
+		n := 1 + rnd.Intn(10)
+		if i%10 == 0 {
+			n = 50 + rnd.Intn(150)
+		}
+		for j := 0; j < n; j++ {
+			switch rnd.Intn(10) {
+			case 0:
+				words = append(words, benchLatin[rnd.Intn(len(benchLatin))])
+			case 1:
+				words = append(words, fmt.Sprintf("%d", rnd.Intn(10000)))
+			case 2:
+				words = append(words, benchWord(rnd, 2+rnd.Intn(3)))
+			case 3:
+				words = append(words, "с.п.а.с.и.б.о")
+			default:
+				words = append(words, natural[rnd.Intn(len(natural))])
+			}
+			if rnd.Intn(8) == 0 {
+				words[len(words)-1] += ","
+			}
+		}
+		messages = append(messages, strings.Join(words, " ")+"!")
+	}
+	return
+}
+
+func BenchmarkNew(b *testing.B) {
+	entries := benchDictionary(rand.New(rand.NewSource(1)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := New(entries); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMatch(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	f := mustNew(b, benchDictionary(rnd)...)
+	messages := benchCorpus(rnd)
+	var size int
+	for _, msg := range messages {
+		size += len(msg)
+	}
+
+	b.SetBytes(int64(size / len(messages)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Match(messages[i%len(messages)])
+	}
+}
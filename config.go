@@ -13,10 +13,15 @@ const (
 
 // Settings is a main struct for settings
 type Settings struct {
-	APIKey        string            `json:"api-key"`
-	Addr          string            `json:"addr"`
-	Couchbase     CouchbaseSettings `json:"couchbase"`
-	StaticDirPath string            `json:"static-dir-path"`
+	APIKey        string             `json:"api-key"`
+	Addr          string             `json:"addr"`
+	BaseURL       string             `json:"base-url"`
+	Couchbase     CouchbaseSettings  `json:"couchbase"`
+	StaticDirPath string             `json:"static-dir-path"`
+	Timezone      string             `json:"timezone"`
+	EditCommands  bool               `json:"edit-commands"`
+	Escalation    EscalationSettings `json:"escalation"`
+	AdminToken    string             `json:"admin-token"`
 }
 
 // CouchbaseSettings is a sub truct for couchbase settings
@@ -26,6 +31,13 @@ type CouchbaseSettings struct {
 	Secret  string `json:"secret"`
 }
 
+// EscalationSettings is a sub struct for default escalation policies of chats,
+// steps are in format level:action[:duration], e.g. 3:warn, 5:mute:1h, 8:ban
+type EscalationSettings struct {
+	Warn []string `json:"warn"`
+	Cens []string `json:"cens"`
+}
+
 // LoadConfig function load a config file
 func LoadConfig() {
 	settings.APIKey = ""
@@ -33,6 +45,9 @@ func LoadConfig() {
 	settings.Couchbase.Cluster = "couchbase://couchbase"
 	settings.Couchbase.Bucket = "default"
 	settings.Couchbase.Secret = ""
+	settings.Timezone = "Local"
+	settings.Escalation.Warn = []string{"5:ban"}
+	settings.Escalation.Cens = []string{"6:ban"}
 
 	f, err := os.Open(configFileName)
 	if err != nil {
@@ -9,43 +9,182 @@ import (
 	"time"
 )
 
-// Cache is struct for store date caches
+// Cache is struct for store date caches of one chat, it is safe for concurrent use
 type Cache struct {
-	Years        []string
-	MonthsByYear map[int][]time.Month
-	Days         map[string][]int
-	mutex        *sync.Mutex
+	years        []string
+	monthsByYear map[int][]time.Month
+	days         map[string][]int
+	counts       map[string]int
+	location     *time.Location
+	loaded       bool
+	mutex        sync.RWMutex
 }
 
-// Caches is a map cache to chat ID
-type Caches map[int64]*Cache
+// Caches is a map cache to chat ID, it is safe for concurrent use
+type Caches struct {
+	chats map[int64]*Cache
+	mutex sync.Mutex
+}
 
 // CreateNewCache function create new Cache pointer
 func CreateNewCache() *Cache {
 	cache := new(Cache)
-	cache.mutex = new(sync.Mutex)
-	cache.MonthsByYear = make(map[int][]time.Month)
-	cache.Days = make(map[string][]int)
+	cache.monthsByYear = make(map[int][]time.Month)
+	cache.days = make(map[string][]int)
+	cache.counts = make(map[string]int)
+	cache.location = defaultLocation
 	return cache
 }
 
-// AddedDateToCaches added date to caches
-func AddedDateToCaches(chatID int64, d time.Time) {
-	if _, ok := caches[chatID]; !ok {
-		caches[chatID] = CreateNewCache()
+// Get returns Cache pointer by Chat ID, new cache created if not exists
+func (c *Caches) Get(chatID int64) *Cache {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.chats == nil {
+		c.chats = make(map[int64]*Cache)
 	}
+	cache, ok := c.chats[chatID]
+	if !ok {
+		cache = CreateNewCache()
+		c.chats[chatID] = cache
+	}
+	return cache
+}
+
+// Add added message date to cache
+func (cache *Cache) Add(d time.Time) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
 
-	cache := caches[chatID]
+	cache.add(d.In(cache.location), 1)
+}
 
+// Load replaces cache content with stored date index
+func (cache *Cache) Load(index *DateIndex) {
 	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.years = nil
+	cache.monthsByYear = make(map[int][]time.Month)
+	cache.days = make(map[string][]int)
+	cache.counts = make(map[string]int)
+	cache.location = index.Location()
+	for day, count := range index.Days {
+		d, err := time.ParseInLocation(dayLayout, day, cache.location)
+		if err != nil {
+			log.Printf("Error in parse date index day [%s] for chat %d: %s", day, index.ChatID, err)
+			continue
+		}
+		cache.add(d, count)
+	}
+	cache.loaded = true
+}
+
+// Loaded returns true if cache was loaded from stored date index
+func (cache *Cache) Loaded() bool {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	return cache.loaded
+}
+
+// Location returns timezone of cache days
+func (cache *Cache) Location() *time.Location {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	return cache.location
+}
+
+func (cache *Cache) add(d time.Time, count int) {
+	cache.counts[getDayKey(d)] += count
+
 	strYear := strconv.Itoa(d.Year())
 	month := d.Month()
 
-	cache.Years = appendIfNotFound(cache.Years, strYear)
-	cache.MonthsByYear[d.Year()] = appendIfNotFoundMonth(cache.MonthsByYear[d.Year()], month)
+	cache.years = appendIfNotFound(cache.years, strYear)
+	cache.monthsByYear[d.Year()] = appendIfNotFoundMonth(cache.monthsByYear[d.Year()], month)
 	id := getYearMonthID(d.Year(), month)
-	cache.Days[id] = appendIfNotFoundInt(cache.Days[id], d.Day())
-	cache.mutex.Unlock()
+	cache.days[id] = appendIfNotFoundInt(cache.days[id], d.Day())
+}
+
+// Years returns sorted copy of years list
+func (cache *Cache) Years() []string {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	result := make([]string, len(cache.years))
+	copy(result, cache.years)
+	sort.Strings(result)
+	return result
+}
+
+// Months returns sorted copy of months list for year
+func (cache *Cache) Months(year int) []time.Month {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	return sortMonths(cache.monthsByYear[year])
+}
+
+// Days returns sorted copy of days list for year and month
+func (cache *Cache) Days(year int, month time.Month) []int {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	list := cache.days[getYearMonthID(year, month)]
+	result := make([]int, len(list))
+	copy(result, list)
+	sort.Ints(result)
+	return result
+}
+
+// DayCounts returns messages count by day of month
+func (cache *Cache) DayCounts(year int, month time.Month) map[int]int {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	result := make(map[int]int)
+	for _, day := range cache.days[getYearMonthID(year, month)] {
+		result[day] = cache.counts[getDayKey(time.Date(year, month, day, 0, 0, 0, 0, cache.location))]
+	}
+	return result
+}
+
+// LastDay returns the last day with messages
+func (cache *Cache) LastDay() (day time.Time, ok bool) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	var last string
+	for key := range cache.counts {
+		if key > last {
+			last = key
+		}
+	}
+	if last == "" {
+		return
+	}
+	day, err := time.ParseInLocation(dayLayout, last, cache.location)
+	return day, err == nil
+}
+
+// AddedDateToCaches added date to caches
+func AddedDateToCaches(chatID int64, d time.Time) {
+	caches.Get(chatID).Add(d)
+}
+
+// getChatCache returns Cache pointer by Chat ID, cache loaded from date index if needed
+func getChatCache(chatID int64) *Cache {
+	cache := caches.Get(chatID)
+	if cache.Loaded() {
+		return cache
+	}
+	if _, err := loadDateIndex(chatID); err != nil {
+		log.Printf("Error in load date index for chat %d: %s", chatID, err)
+	}
+	return cache
 }
 
 func updateDateCaches() {
@@ -54,28 +193,11 @@ func updateDateCaches() {
 		return
 	}
 	for _, chat := range chats {
-		chatID := chat.ID
-		listDates, err := getDates(chatID, 0, 0)
-		if err != nil {
-			return
-		}
-		for _, t := range listDates {
-			AddedDateToCaches(chatID, t)
+		if _, err := loadDateIndex(chat.ID); err != nil {
+			log.Printf("Error in load date index for chat %d: %s", chat.ID, err)
 		}
 	}
 	log.Printf("Time caches updated.")
-	//go updateDateCaches()
-}
-
-// GetCache function returns Cache pointer by Chat ID
-func getCache(chatID int64) *Cache {
-	if cache, ok := caches[chatID]; ok {
-		return cache
-	}
-
-	cache := CreateNewCache()
-	caches[chatID] = cache
-	return cache
 }
 
 func sortMonths(a []time.Month) (result []time.Month) {
@@ -90,16 +212,6 @@ func sortMonths(a []time.Month) (result []time.Month) {
 	return
 }
 
-func getDays(chatID int64, year int, month time.Month) []int {
-	id := getYearMonthID(year, month)
-	cache := getCache(chatID)
-	if result, ok := cache.Days[id]; ok {
-		sort.Ints(result)
-		return result
-	}
-	return []int{}
-}
-
 func getYearMonthID(year int, month time.Month) string {
 	return fmt.Sprintf("%d/%d", year, month)
 }
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCachesConcurrent(t *testing.T) {
+	const (
+		workers = 8
+		rounds  = 200
+	)
+	var c Caches
+	day := time.Date(2018, time.March, 8, 12, 0, 0, 0, defaultLocation)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				c.Get(int64(w % 2)).Add(day)
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				cache := c.Get(int64(w % 2))
+				cache.Years()
+				cache.Months(2018)
+				cache.Days(2018, time.March)
+				cache.DayCounts(2018, time.March)
+				cache.LastDay()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for chatID := int64(0); chatID < 2; chatID++ {
+		counts := c.Get(chatID).DayCounts(2018, time.March)
+		if counts[8] != workers/2*rounds {
+			t.Fatalf("chat %d: count = %d, want %d", chatID, counts[8], workers/2*rounds)
+		}
+	}
+}
+
+func TestCacheLoadConcurrent(t *testing.T) {
+	var c Caches
+	index := &DateIndex{ChatID: 1, Days: map[string]int{"2018-03-08": 5}}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c.Get(1).Load(index)
+		}()
+		go func() {
+			defer wg.Done()
+			c.Get(1).Loaded()
+			c.Get(1).Years()
+		}()
+	}
+	wg.Wait()
+
+	if !c.Get(1).Loaded() {
+		t.Fatal("cache is not loaded")
+	}
+	if days := c.Get(1).Days(2018, time.March); len(days) != 1 || days[0] != 8 {
+		t.Fatalf("Days() = %v, want [8]", days)
+	}
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	couchbase "github.com/couchbase/gocb"
+)
+
+// Captcha modes
+const (
+	CaptchaModeButton = "button" // press the button
+	CaptchaModeMath   = "math"   // choose answer of simple arithmetic
+)
+
+const defaultCaptchaTimeout = 5 // minutes
+
+// CaptchaSettings settings of captcha for new chat members
+type CaptchaSettings struct {
+	Enabled bool   `json:"enabled"`
+	Mode    string `json:"mode,omitempty"`    // empty means CaptchaModeButton
+	Timeout int    `json:"timeout,omitempty"` // minutes, 0 means default
+}
+
+// Challenge main struct for records captcha:chat_id:user_id, it is a captcha pending for new member
+type Challenge struct {
+	ChatID    int64  `json:"chat_id"`
+	UserID    int    `json:"user_id"`
+	User      string `json:"user"`
+	MessageID int    `json:"message_id"` // message with challenge
+	Answer    string `json:"answer"`
+	Muted     bool   `json:"muted,omitempty"` // member was muted before captcha, the mute is kept after it
+	Deadline  int64  `json:"deadline"`
+	Date      int64  `json:"date"`
+	Type      string `json:"type"`
+}
+
+// GetMode returns captcha mode
+func (settings CaptchaSettings) GetMode() string {
+	if settings.Mode == "" {
+		return CaptchaModeButton
+	}
+	return settings.Mode
+}
+
+// GetTimeout returns time for captcha solving
+func (settings CaptchaSettings) GetTimeout() time.Duration {
+	if settings.Timeout <= 0 {
+		return defaultCaptchaTimeout * time.Minute
+	}
+	return time.Duration(settings.Timeout) * time.Minute
+}
+
+func getChallengeKey(chatID int64, userID int) string {
+	return fmt.Sprintf("captcha:%d:%d", chatID, userID)
+}
+
+// SaveChallenge stores pending captcha
+func SaveChallenge(c *Challenge) (err error) {
+	c.Type = "captcha"
+	_, err = bucket.Upsert(getChallengeKey(c.ChatID, c.UserID), c, 0)
+	return
+}
+
+// GetChallenge returns pending captcha of user in chat, nil is returned if user has no captcha
+func GetChallenge(chatID int64, userID int) (c *Challenge, err error) {
+	c = new(Challenge)
+	if _, err = bucket.Get(getChallengeKey(chatID, userID), c); err == couchbase.ErrKeyNotFound {
+		return nil, nil
+	}
+	return
+}
+
+// RemoveChallenge removes pending captcha, missing record is not an error
+func RemoveChallenge(chatID int64, userID int) (err error) {
+	if _, err = bucket.Remove(getChallengeKey(chatID, userID), 0); err == couchbase.ErrKeyNotFound {
+		err = nil
+	}
+	return
+}
+
+// GetExpiredChallenges returns pending captchas with deadline before time t
+func GetExpiredChallenges(t time.Time) (challenges []*Challenge, err error) {
+	queryStr := fmt.Sprintf("SELECT bot.* FROM %s AS bot WHERE type='captcha' AND deadline<=%d ORDER BY deadline", bucketName, t.Unix())
+	query := couchbase.NewN1qlQuery(queryStr).Consistency(couchbase.RequestPlus)
+	res, err := bucket.ExecuteN1qlQuery(query, nil)
+	if err != nil {
+		return
+	}
+
+	c := new(Challenge)
+	for res.Next(c) {
+		challenges = append(challenges, c)
+		c = new(Challenge)
+	}
+	err = res.Close()
+	return
+}
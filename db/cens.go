@@ -0,0 +1,73 @@
+package db
+
+import (
+	"fmt"
+
+	couchbase "github.com/couchbase/gocb"
+)
+
+// Actions on cens words found in message
+const (
+	CensActionReply  = "reply"  // reply with scolding and count
+	CensActionDelete = "delete" // delete message and count
+	CensActionCount  = "count"  // count silently
+)
+
+// Exempt roles of cens check, users with the role or higher aren't checked
+const (
+	CensExemptNone      = "none"
+	CensExemptTrusted   = "trusted"
+	CensExemptModerator = "moderator"
+	CensExemptAdmin     = "admin"
+)
+
+// CensSettings settings of cens check in chat
+type CensSettings struct {
+	Enabled      bool     `json:"enabled"`
+	Action       string   `json:"action,omitempty"`       // empty means CensActionReply
+	Dictionaries []string `json:"dictionaries,omitempty"` // empty means default dictionary
+	ExemptRole   string   `json:"exempt_role,omitempty"`  // empty means CensExemptTrusted
+}
+
+// CensWords main struct for records censwords:chat_id, words are added to chat dictionaries
+type CensWords struct {
+	ChatID int64    `json:"chat_id"`
+	Words  []string `json:"words"`
+	Type   string   `json:"type"`
+}
+
+// GetAction returns action on cens words
+func (settings CensSettings) GetAction() string {
+	if settings.Action == "" {
+		return CensActionReply
+	}
+	return settings.Action
+}
+
+// GetExemptRole returns exempt role of cens check
+func (settings CensSettings) GetExemptRole() string {
+	if settings.ExemptRole == "" {
+		return CensExemptTrusted
+	}
+	return settings.ExemptRole
+}
+
+func getCensWordsKey(chatID int64) string {
+	return fmt.Sprintf("censwords:%d", chatID)
+}
+
+// GetCensWords returns words added to chat dictionaries, empty list returned if chat has no words
+func GetCensWords(chatID int64) (words *CensWords, err error) {
+	words = &CensWords{ChatID: chatID, Type: "censwords"}
+	if _, err = bucket.Get(getCensWordsKey(chatID), words); err == couchbase.ErrKeyNotFound {
+		err = nil
+	}
+	return
+}
+
+// SaveCensWords stores words added to chat dictionaries
+func SaveCensWords(words *CensWords) (err error) {
+	words.Type = "censwords"
+	_, err = bucket.Upsert(getCensWordsKey(words.ChatID), words, 0)
+	return
+}
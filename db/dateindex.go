@@ -0,0 +1,141 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	couchbase "github.com/couchbase/gocb"
+)
+
+const (
+	dayLayout  = "2006-01-02"
+	casRetries = 10
+)
+
+// DateIndex main struct for records dateindex:chat_id, it stores messages count per day,
+// days are counted in chat timezone
+type DateIndex struct {
+	ChatID   int64          `json:"chat_id"`
+	Days     map[string]int `json:"days"`
+	Timezone string         `json:"timezone"`
+	Type     string         `json:"type"`
+}
+
+// Location returns timezone of index days
+func (index *DateIndex) Location() *time.Location {
+	loc, err := time.LoadLocation(index.Timezone)
+	if err != nil {
+		return defaultLocation
+	}
+	return loc
+}
+
+func getDateIndexKey(chatID int64) string {
+	return fmt.Sprintf("dateindex:%d", chatID)
+}
+
+func getDayKey(d time.Time) string {
+	return d.Format(dayLayout)
+}
+
+// incDateIndex added +1 to messages count for day of d in chat date index
+func incDateIndex(chatID int64, d time.Time) (err error) {
+	key := getDateIndexKey(chatID)
+
+	for i := 0; i < casRetries; i++ {
+		index := DateIndex{}
+		var cas couchbase.Cas
+		if cas, err = bucket.Get(key, &index); err == couchbase.ErrKeyNotFound {
+			// index is not exists yet, build it from stored messages with current message
+			if _, err = buildDateIndex(chatID); err == couchbase.ErrKeyExists {
+				// index was built meanwhile from stored messages, current message is counted there
+				err = nil
+			}
+			return
+		} else if err != nil {
+			return
+		}
+
+		if index.Days == nil {
+			index.Days = make(map[string]int)
+		}
+		index.Days[getDayKey(d.In(index.Location()))]++
+		if _, err = bucket.Replace(key, &index, cas, 0); err == couchbase.ErrKeyExists {
+			// changed by someone else, try again
+			continue
+		}
+		return
+	}
+	return fmt.Errorf("Date index for chat %d is busy", chatID)
+}
+
+// newDateIndex scans all chat messages and returns new date index in chat timezone
+func newDateIndex(chatID int64) (index *DateIndex, err error) {
+	listDates, err := getDates(chatID, 0, 0)
+	if err != nil {
+		return
+	}
+
+	loc := GetChatLocation(chatID)
+	index = &DateIndex{ChatID: chatID, Days: make(map[string]int), Timezone: loc.String(), Type: "dateindex"}
+	for _, t := range listDates {
+		index.Days[getDayKey(t.In(loc))]++
+	}
+	return
+}
+
+// buildDateIndex scans all chat messages and stores new date index
+func buildDateIndex(chatID int64) (index *DateIndex, err error) {
+	if index, err = newDateIndex(chatID); err != nil {
+		return
+	}
+	_, err = bucket.Insert(getDateIndexKey(chatID), index, 0)
+	return
+}
+
+// rebuildDateIndex scans all chat messages, replaces stored date index and reloads chat cache
+func rebuildDateIndex(chatID int64) (index *DateIndex, err error) {
+	if index, err = newDateIndex(chatID); err != nil {
+		return
+	}
+	if _, err = bucket.Upsert(getDateIndexKey(chatID), index, 0); err != nil {
+		return
+	}
+	caches.Get(chatID).Load(index)
+	return
+}
+
+// getDateIndex returns stored date index for chat, the index will be built if not exists
+// or rebuilt if chat timezone was changed
+func getDateIndex(chatID int64) (index *DateIndex, err error) {
+	index = new(DateIndex)
+	if _, err = bucket.Get(getDateIndexKey(chatID), index); err == nil {
+		if loc := GetChatLocation(chatID); index.Timezone != loc.String() {
+			log.Printf("Date index for chat %d has timezone [%s] instead [%s], rebuild it.", chatID, index.Timezone, loc)
+			return rebuildDateIndex(chatID)
+		}
+		return
+	} else if err != couchbase.ErrKeyNotFound {
+		return
+	}
+
+	log.Printf("Date index for chat %d not found, build it.", chatID)
+	if index, err = buildDateIndex(chatID); err == couchbase.ErrKeyExists {
+		index = new(DateIndex)
+		_, err = bucket.Get(getDateIndexKey(chatID), index)
+	}
+	return
+}
+
+// loadDateIndex fills chat cache from stored date index
+func loadDateIndex(chatID int64) (cache *Cache, err error) {
+	index, err := getDateIndex(chatID)
+	if err != nil {
+		return
+	}
+
+	cache = caches.Get(chatID)
+	cache.Load(index)
+	return
+}
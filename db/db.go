@@ -4,8 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"sort"
-	"strconv"
 	"strings"
 	"time"
 
@@ -20,17 +18,13 @@ var (
 	caches     Caches
 )
 
-// CensLevel main struct for records censlevel:year:id
+// CensLevel main struct for records censlevel:chat_id:year:id
 type CensLevel struct {
-	ID    int `json:"user_id"`
-	Level int `json:"level"`
-	Year  int `json:"year"`
-}
-
-// WarnLevel main struct for records warnlevel:id
-type WarnLevel struct {
-	ID    int `json:"user_id"`
-	Level int `json:"level"`
+	ID     int    `json:"user_id"`
+	ChatID int64  `json:"chat_id"`
+	Level  int    `json:"level"`
+	Year   int    `json:"year"`
+	Type   string `json:"type"`
 }
 
 // InitCouchbase function initialize couchbase bucket with parameters
@@ -45,7 +39,8 @@ func InitCouchbase(couchbaseCluster, couchbaseBucket, couchbaseSecret string) {
 	}
 	bucketName = couchbaseBucket
 
-	caches = make(Caches)
+	migrateLevels()
+	migrateWarnLevels()
 	updateDateCaches()
 }
 
@@ -57,25 +52,63 @@ func GoSaveMessage(msg *tgbotapi.Message) {
 	}
 }
 
+// GoSaveEditedMessage is a shell method for goroutine SaveEditedMessage
+func GoSaveEditedMessage(msg *tgbotapi.Message) {
+	err := SaveEditedMessage(msg)
+	if err != nil {
+		log.Printf("Error per save edited message: %s", err.Error())
+	}
+}
+
 // SaveMessage method save message to database
 func SaveMessage(msg *tgbotapi.Message) (err error) {
-	go AddedDateToCaches(msg.Chat.ID, msg.Time())
-	key := fmt.Sprintf("message:%d:%d", msg.Chat.ID, msg.MessageID)
+	return saveMessage(msg, false)
+}
 
-	type couchmessage struct {
-		tgbotapi.Message
-		Type string `json:"type"`
-	}
-	cMsg := couchmessage{}
+// SaveEditedMessage method save edited message to database
+func SaveEditedMessage(msg *tgbotapi.Message) (err error) {
+	return saveMessage(msg, true)
+}
+
+type couchmessage struct {
+	tgbotapi.Message
+	Type string `json:"type"`
+}
+
+func getMessageKey(msg *tgbotapi.Message) string {
+	return fmt.Sprintf("message:%d:%d", msg.Chat.ID, msg.MessageID)
+}
 
+func newCouchMessage(msg *tgbotapi.Message) (cMsg *couchmessage, err error) {
 	data, err := json.Marshal(msg)
 	if err != nil {
 		return
 	}
-	err = json.Unmarshal(data, &cMsg)
+	cMsg = new(couchmessage)
+	err = json.Unmarshal(data, cMsg)
 	cMsg.Type = "message"
+	return
+}
 
-	_, err = bucket.Upsert(key, &cMsg, 0)
+func saveMessage(msg *tgbotapi.Message, edited bool) (err error) {
+	key := getMessageKey(msg)
+	cMsg, err := newCouchMessage(msg)
+	if err != nil {
+		return
+	}
+
+	// count only new messages in date index, edited messages already counted
+	if _, err = bucket.Insert(key, cMsg, 0); err == couchbase.ErrKeyExists {
+		if _, err = bucket.Upsert(key, cMsg, 0); err == nil && edited {
+			Events.Publish(msg.Chat.ID, MessageEvent{Type: EventEditedMessage, Message: msg})
+		}
+	} else if err == nil {
+		if err = incDateIndex(msg.Chat.ID, msg.Time()); err != nil {
+			log.Printf("Error in update date index for chat %d: %s", msg.Chat.ID, err)
+		}
+		AddedDateToCaches(msg.Chat.ID, msg.Time())
+		Events.Publish(msg.Chat.ID, MessageEvent{Type: EventNewMessage, Message: msg})
+	}
 
 	if msg.Chat != nil {
 		err = SaveChat(msg.Chat, false)
@@ -87,18 +120,37 @@ func SaveMessage(msg *tgbotapi.Message) (err error) {
 		err = SaveChat(msg.ForwardFromChat, true)
 	}
 	if msg.ReplyToMessage != nil {
-		err = SaveMessage(msg.ReplyToMessage)
+		err = saveReplyMessage(msg.ReplyToMessage)
 	}
 	if msg.From != nil {
 		err = SaveUser(msg.From)
 	}
-	if msg.NewChatMember != nil {
-		err = SaveUser(msg.NewChatMember)
+	if msg.NewChatMembers != nil {
+		for i := range *msg.NewChatMembers {
+			err = SaveUser(&(*msg.NewChatMembers)[i])
+		}
 	}
 
 	return
 }
 
+// saveReplyMessage stores replied message if it is missing in database, it is a partial copy
+// of message posted earlier, so stored message isn't replaced, counted or published as new
+func saveReplyMessage(msg *tgbotapi.Message) (err error) {
+	cMsg, err := newCouchMessage(msg)
+	if err != nil {
+		return
+	}
+	if _, err = bucket.Insert(getMessageKey(msg), cMsg, 0); err == couchbase.ErrKeyExists {
+		err = nil
+	}
+
+	if msg.From != nil {
+		err = SaveUser(msg.From)
+	}
+	return
+}
+
 // SaveUser method save user to database
 func SaveUser(user *tgbotapi.User) (err error) {
 	key := fmt.Sprintf("user:%d", user.ID)
@@ -141,28 +193,16 @@ func SaveFile(file *tgbotapi.File, chatID int64) (err error) {
 	return
 }
 
-// GetCensLevel function returns censore level for user
-func GetCensLevel(user *tgbotapi.User) (currentLevel int, err error) {
-	currentLevel = 0
-	currentYear := time.Now().Year()
-	key := fmt.Sprintf("censlevel:%d:%d", currentYear, user.ID)
-
-	level := CensLevel{}
-
-	_, err = bucket.Get(key, &level)
-	if err != nil {
-		return
-	}
-	currentLevel = level.Level
-	return
+func getCensLevelKey(chatID int64, year, userID int) string {
+	return fmt.Sprintf("censlevel:%d:%d:%d", chatID, year, userID)
 }
 
-// GetWarnLevel function returns warning level for user
-func GetWarnLevel(user *tgbotapi.User) (currentLevel int, err error) {
+// GetCensLevel function returns censore level for user in chat
+func GetCensLevel(chatID int64, user *tgbotapi.User) (currentLevel int, err error) {
 	currentLevel = 0
-	key := fmt.Sprintf("warnlevel:%d", user.ID)
+	key := getCensLevelKey(chatID, time.Now().Year(), user.ID)
 
-	level := WarnLevel{}
+	level := CensLevel{}
 
 	_, err = bucket.Get(key, &level)
 	if err != nil {
@@ -172,38 +212,17 @@ func GetWarnLevel(user *tgbotapi.User) (currentLevel int, err error) {
 	return
 }
 
-// SetCensLevel function sets level for user
-func SetCensLevel(user *tgbotapi.User, setlevel int) (err error) {
+// SetCensLevel function sets level for user in chat
+func SetCensLevel(chatID int64, user *tgbotapi.User, setlevel int) (err error) {
 	currentYear := time.Now().Year()
-	key := fmt.Sprintf("censlevel:%d:%d", currentYear, user.ID)
-
-	level := CensLevel{}
+	key := getCensLevelKey(chatID, currentYear, user.ID)
 
-	_, err = bucket.Get(key, &level)
-	if err != nil {
-		level.ID = user.ID
-		level.Level = setlevel
-		level.Year = currentYear
-	} else {
-		level.Level = setlevel
-	}
-
-	_, err = bucket.Upsert(key, &level, 0)
-	return
-}
-
-// SetWarnLevel function sets level for user
-func SetWarnLevel(user *tgbotapi.User, setlevel int) (err error) {
-	key := fmt.Sprintf("warnlevel:%d", user.ID)
-
-	level := WarnLevel{}
-
-	_, err = bucket.Get(key, &level)
-	if err != nil {
-		level.ID = user.ID
-		level.Level = setlevel
-	} else {
-		level.Level = setlevel
+	level := CensLevel{
+		ID:     user.ID,
+		ChatID: chatID,
+		Level:  setlevel,
+		Year:   currentYear,
+		Type:   "censlevel",
 	}
 
 	_, err = bucket.Upsert(key, &level, 0)
@@ -211,9 +230,8 @@ func SetWarnLevel(user *tgbotapi.User, setlevel int) (err error) {
 }
 
 // ClearCensLevel remove document from bucket
-func ClearCensLevel(user *tgbotapi.User) (err error) {
-	currentYear := time.Now().Year()
-	key := fmt.Sprintf("censlevel:%d:%d", currentYear, user.ID)
+func ClearCensLevel(chatID int64, user *tgbotapi.User) (err error) {
+	key := getCensLevelKey(chatID, time.Now().Year(), user.ID)
 
 	level := CensLevel{}
 
@@ -229,47 +247,25 @@ func ClearCensLevel(user *tgbotapi.User) (err error) {
 	return
 }
 
-// ClearWarnLevel remove document from bucket
-func ClearWarnLevel(user *tgbotapi.User) (err error) {
-	key := fmt.Sprintf("warnlevel:%d", user.ID)
-	level := WarnLevel{}
-
-	var cas couchbase.Cas
-	if cas, err = bucket.Get(key, &level); err != nil {
-		return
-	} else {
-		if _, err = bucket.Remove(key, cas); err != nil {
-			return
-		}
-	}
-	return
-}
-
 // AddCensLevel added +1 to cens level in year
-func AddCensLevel(user *tgbotapi.User) (currentLevel int, err error) {
-	currentLevel, err = GetCensLevel(user)
+func AddCensLevel(chatID int64, user *tgbotapi.User) (currentLevel int, err error) {
+	currentLevel, err = GetCensLevel(chatID, user)
 	if err != nil {
 		currentLevel = 1
-		err = SetCensLevel(user, currentLevel)
+		err = SetCensLevel(chatID, user, currentLevel)
 		return
 	}
 	currentLevel++
-	err = SetCensLevel(user, currentLevel)
+	err = SetCensLevel(chatID, user, currentLevel)
 
 	return
 }
 
-// AddWarnLevel added +1 to warning level for user
-func AddWarnLevel(user *tgbotapi.User) (currentLevel int, err error) {
-	if currentLevel, err = GetWarnLevel(user); err != nil {
-		if err == couchbase.ErrKeyNotFound {
-			currentLevel = 1
-			err = SetWarnLevel(user, currentLevel)
-		}
-		return
-	}
-	currentLevel++
-	err = SetWarnLevel(user, currentLevel)
+// GetMessage returns message by chat ID and message ID
+func GetMessage(chatID int64, messageID int) (msg *tgbotapi.Message, err error) {
+	key := fmt.Sprintf("message:%d:%d", chatID, messageID)
+	msg = new(tgbotapi.Message)
+	_, err = bucket.Get(key, msg)
 	return
 }
 
@@ -306,6 +302,14 @@ func SaveChat(chat *tgbotapi.Chat, forward bool) (err error) {
 	return
 }
 
+// GetChat returns chat by ID
+func GetChat(chatID int64) (chat *tgbotapi.Chat, err error) {
+	key := fmt.Sprintf("chat:%d", chatID)
+	chat = new(tgbotapi.Chat)
+	_, err = bucket.Get(key, chat)
+	return
+}
+
 // GetChats returns chat list
 func GetChats() (chats []*tgbotapi.Chat, err error) {
 	type couchchat struct {
@@ -342,69 +346,23 @@ func GetChats() (chats []*tgbotapi.Chat, err error) {
 
 // GetMessages returns chat list
 func GetMessages(chatID int64) (messages []*tgbotapi.Message, err error) {
-	type couchmsg struct {
-		Msg tgbotapi.Message `json:"bot"`
-	}
-
-	queryStr := fmt.Sprintf("SELECT * FROM %s AS bot WHERE type='message' AND chat.id=%d ORDER BY date", bucketName, chatID)
-	query := couchbase.NewN1qlQuery(queryStr)
-	res, err := bucket.ExecuteN1qlQuery(query, nil)
-	if err != nil {
-		return
-	}
-
-	//var data interface{}
-
-	chat := couchmsg{}
-	for res.Next(&chat) {
-		data, err := json.Marshal(chat.Msg)
-		if err != nil {
-			log.Printf("Error in marshal GetMessages: %s", err)
-			continue
-		}
-		oMsg := new(tgbotapi.Message)
-		err = json.Unmarshal(data, oMsg)
-		if err != nil {
-			log.Printf("Error in unmarshal GetMessages: %s", err)
-			continue
-		}
-		messages = append(messages, oMsg)
-	}
-
-	return
+	return getAllMessages(chatID, time.Time{}, time.Time{})
 }
 
 // GetMessagesByDate returns chat list on date
 func GetMessagesByDate(chatID int64, beginTime, endTime time.Time) (messages []*tgbotapi.Message, err error) {
-	type couchmsg struct {
-		Msg tgbotapi.Message `json:"bot"`
-	}
+	return getAllMessages(chatID, beginTime, endTime)
+}
 
-	queryStr := fmt.Sprintf("SELECT * FROM %s AS bot WHERE type='message' AND chat.id=%d AND date >= %d AND date <= %d ORDER BY date", bucketName, chatID, beginTime.Unix(), endTime.Unix())
-	query := couchbase.NewN1qlQuery(queryStr)
-	res, err := bucket.ExecuteN1qlQuery(query, nil)
+func getAllMessages(chatID int64, beginTime, endTime time.Time) (messages []*tgbotapi.Message, err error) {
+	it, err := IterateMessages(chatID, beginTime, endTime, MessageCursor{}, 0)
 	if err != nil {
 		return
 	}
-
-	//var data interface{}
-
-	chat := couchmsg{}
-	for res.Next(&chat) {
-		data, err := json.Marshal(chat.Msg)
-		if err != nil {
-			log.Printf("Error in marshal GetMessages: %s", err)
-			continue
-		}
-		oMsg := new(tgbotapi.Message)
-		err = json.Unmarshal(data, oMsg)
-		if err != nil {
-			log.Printf("Error in unmarshal GetMessages: %s", err)
-			continue
-		}
-		messages = append(messages, oMsg)
+	for it.Next() {
+		messages = append(messages, it.Message())
 	}
-
+	err = it.Close()
 	return
 }
 
@@ -453,7 +411,8 @@ func getDates(chatID int64, beginDate, endDate int64) (result []time.Time, err e
 	}
 
 	queryStr := fmt.Sprintf("SELECT date FROM %s WHERE type='message' AND chat.id=%d %s ORDER BY date", bucketName, chatID, dateWhere)
-	query := couchbase.NewN1qlQuery(queryStr)
+	// date index is built from result, so just stored messages must be there
+	query := couchbase.NewN1qlQuery(queryStr).Consistency(couchbase.RequestPlus)
 	res, err := bucket.ExecuteN1qlQuery(query, nil)
 	if err != nil {
 		return
@@ -467,6 +426,29 @@ func getDates(chatID int64, beginDate, endDate int64) (result []time.Time, err e
 	return
 }
 
+func getLastDate(chatID int64) (result time.Time, err error) {
+	type couchdate struct {
+		Date int64 `json:"date"`
+	}
+
+	queryStr := fmt.Sprintf("SELECT MAX(date) AS date FROM %s WHERE type='message' AND chat.id=%d", bucketName, chatID)
+	query := couchbase.NewN1qlQuery(queryStr)
+	res, err := bucket.ExecuteN1qlQuery(query, nil)
+	if err != nil {
+		return
+	}
+
+	date := couchdate{}
+	if err = res.One(&date); err != nil {
+		return
+	}
+	if date.Date == 0 {
+		return result, fmt.Errorf("Messages for chat %d not found", chatID)
+	}
+	result = time.Unix(date.Date, 0)
+	return
+}
+
 func appendIfNotFound(list []string, s string) []string {
 	found := false
 	for _, value := range list {
@@ -512,69 +494,91 @@ func appendIfNotFoundInt(list []int, s int) []int {
 	return list
 }
 
-// GetYears function returns years msg date from chat messages
-func GetYears(chatID int64) (result []string, err error) {
-	years := getCache(chatID).Years
-	if len(years) != 0 {
-		sort.Strings(years)
-		return years, nil
+// getChatCacheIn returns chat cache with days in timezone loc, the cache for timezone
+// other than chat timezone is built from messages between beginDate and endDate
+func getChatCacheIn(chatID int64, loc *time.Location, beginDate, endDate int64) (cache *Cache, err error) {
+	cache = getChatCache(chatID)
+	if loc == nil || loc.String() == cache.Location().String() {
+		return
 	}
-	listDates, err := getDates(chatID, 0, 0)
+
+	listDates, err := getDates(chatID, beginDate, endDate)
 	if err != nil {
 		return
 	}
+	cache = CreateNewCache()
+	cache.location = loc
 	for _, t := range listDates {
-		go AddedDateToCaches(chatID, t)
-		s := strconv.Itoa(t.Year())
-		result = appendIfNotFound(result, s)
+		cache.Add(t)
 	}
 	return
 }
 
-// GetMonthList function returns month list msg date from chat messages and year
-func GetMonthList(chatID int64, year int) (result []time.Month, err error) {
-	cache := getCache(chatID)
-	if list, ok := cache.MonthsByYear[year]; ok {
-		if len(list) > 0 {
-			return sortMonths(list), nil
-		}
+func getMonthRange(year int, month time.Month, loc *time.Location) (beginDate, endDate int64) {
+	if loc == nil {
+		return
 	}
-	beginDate := time.Date(year, 1, 1, 0, 0, 0, 0, time.Local).Unix()
-	endDate := time.Date(year, 12, 31, 23, 59, 59, 100, time.Local).Unix()
-	listDates, err := getDates(chatID, beginDate, endDate)
+	beginTime := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	return beginTime.Unix(), beginTime.AddDate(0, 1, 0).Unix() - 1
+}
+
+// GetYears function returns years msg date from chat date index in timezone loc
+func GetYears(chatID int64, loc *time.Location) (result []string, err error) {
+	cache, err := getChatCacheIn(chatID, loc, 0, 0)
 	if err != nil {
 		return
 	}
-	for _, t := range listDates {
-		if t.Year() != year {
-			continue
-		}
+	return cache.Years(), nil
+}
 
-		result = appendIfNotFoundMonth(result, t.Month())
+// GetMonthList function returns month list msg date from chat date index and year in timezone loc
+func GetMonthList(chatID int64, year int, loc *time.Location) (result []time.Month, err error) {
+	var beginDate, endDate int64
+	if loc != nil {
+		beginDate = time.Date(year, 1, 1, 0, 0, 0, 0, loc).Unix()
+		endDate = time.Date(year+1, 1, 1, 0, 0, 0, 0, loc).Unix() - 1
 	}
-	return
-
+	cache, err := getChatCacheIn(chatID, loc, beginDate, endDate)
+	if err != nil {
+		return
+	}
+	return cache.Months(year), nil
 }
 
-// GetDates function returns month list msg date from chat messages and year
-func GetDates(chatID int64, year int, month int) (result []int, err error) {
-	result = getDays(chatID, year, time.Month(month))
-	if len(result) > 0 {
+// GetDates function returns days list msg date from chat date index, year and month in timezone loc
+func GetDates(chatID int64, year int, month int, loc *time.Location) (result []int, err error) {
+	beginDate, endDate := getMonthRange(year, time.Month(month), loc)
+	cache, err := getChatCacheIn(chatID, loc, beginDate, endDate)
+	if err != nil {
 		return
 	}
-	beginTime := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
-	beginDate := beginTime.Unix()
-	endDate := time.Date(year, time.Month(month), 32, 23, 59, 59, 100, time.Local).Unix()
-	listDates, err := getDates(chatID, beginDate, endDate)
+	return cache.Days(year, time.Month(month)), nil
+}
+
+// GetDayCounts function returns messages count by day from chat date index, year and month in timezone loc
+func GetDayCounts(chatID int64, year int, month int, loc *time.Location) (result map[int]int, err error) {
+	beginDate, endDate := getMonthRange(year, time.Month(month), loc)
+	cache, err := getChatCacheIn(chatID, loc, beginDate, endDate)
 	if err != nil {
 		return
 	}
-	for _, t := range listDates {
-		if t.Year() == year && t.Month() == time.Month(month) {
-			result = appendIfNotFoundInt(result, t.Day())
-		}
+	return cache.DayCounts(year, time.Month(month)), nil
+}
+
+// GetLastDate function returns the last day with messages from chat date index in timezone loc
+func GetLastDate(chatID int64, loc *time.Location) (day time.Time, ok bool) {
+	cache := getChatCache(chatID)
+	if loc == nil || loc.String() == cache.Location().String() {
+		return cache.LastDay()
 	}
-	return
+
+	last, err := getLastDate(chatID)
+	if err != nil {
+		log.Printf("Error in getLastDate for chat %d: %s", chatID, err)
+		return
+	}
+	last = last.In(loc)
+	return time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, loc), true
 }
 
 // GetUser get user by username or first and last name
@@ -634,3 +638,12 @@ func GetUser(username string) (user *tgbotapi.User, err error) {
 
 	return
 }
+
+// GetUserByID returns user by Telegram user ID
+func GetUserByID(userID int) (user *tgbotapi.User, err error) {
+	user = new(tgbotapi.User)
+	if _, err = bucket.Get(fmt.Sprintf("user:%d", userID), user); err != nil {
+		return nil, err
+	}
+	return
+}
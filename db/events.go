@@ -0,0 +1,74 @@
+package db
+
+import (
+	"sync"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// Message event types
+const (
+	EventNewMessage     = "new"
+	EventEditedMessage  = "edit"
+	EventRemovedMessage = "remove" // message removed by moderation
+)
+
+const eventsBufferSize = 64
+
+// MessageEvent is an event about saved message
+type MessageEvent struct {
+	Type    string
+	Message *tgbotapi.Message
+}
+
+// Bus is an in-process publisher of message events to chat subscribers, it is safe for concurrent use
+type Bus struct {
+	subscribers map[int64]map[chan MessageEvent]struct{}
+	mutex       sync.RWMutex
+}
+
+// Events is a bus for saved messages events
+var Events Bus
+
+// Subscribe returns channel with events of chat, cancel must be called when events are not needed anymore
+func (b *Bus) Subscribe(chatID int64) (events <-chan MessageEvent, cancel func()) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.subscribers == nil {
+		b.subscribers = make(map[int64]map[chan MessageEvent]struct{})
+	}
+	if b.subscribers[chatID] == nil {
+		b.subscribers[chatID] = make(map[chan MessageEvent]struct{})
+	}
+	ch := make(chan MessageEvent, eventsBufferSize)
+	b.subscribers[chatID][ch] = struct{}{}
+
+	var once sync.Once
+	cancel = func() {
+		once.Do(func() {
+			b.mutex.Lock()
+			defer b.mutex.Unlock()
+
+			delete(b.subscribers[chatID], ch)
+			if len(b.subscribers[chatID]) == 0 {
+				delete(b.subscribers, chatID)
+			}
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+// Publish sends event to all chat subscribers, the event is dropped for subscribers with full buffer
+func (b *Bus) Publish(chatID int64, event MessageEvent) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for ch := range b.subscribers[chatID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
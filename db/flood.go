@@ -0,0 +1,60 @@
+package db
+
+import (
+	"time"
+
+	"github.com/elemc/gotelegrambot/escalation"
+)
+
+// Actions on flood
+const (
+	FloodActionWarn   = "warn"   // issue warning
+	FloodActionMute   = "mute"   // mute user for mute duration
+	FloodActionDelete = "delete" // delete messages of burst
+)
+
+// Default flood settings
+const (
+	defaultFloodMessages     = 10
+	defaultFloodSeconds      = 10
+	defaultFloodMuteDuration = escalation.Duration(10 * time.Minute)
+)
+
+var defaultFloodActions = []string{FloodActionDelete, FloodActionMute}
+
+// FloodSettings settings of flood protection in chat, flood is more than Messages messages in Seconds seconds
+type FloodSettings struct {
+	Enabled      bool                `json:"enabled"`
+	Messages     int                 `json:"messages,omitempty"`      // 0 means default
+	Seconds      int                 `json:"seconds,omitempty"`       // 0 means default
+	Actions      []string            `json:"actions,omitempty"`       // empty means default
+	MuteDuration escalation.Duration `json:"mute_duration,omitempty"` // 0 means default
+}
+
+// GetLimit returns maximum of messages in window
+func (settings FloodSettings) GetLimit() (messages int, window time.Duration) {
+	messages, seconds := settings.Messages, settings.Seconds
+	if messages <= 0 {
+		messages = defaultFloodMessages
+	}
+	if seconds <= 0 {
+		seconds = defaultFloodSeconds
+	}
+	return messages, time.Duration(seconds) * time.Second
+}
+
+// GetActions returns actions on flood
+func (settings FloodSettings) GetActions() []string {
+	if len(settings.Actions) == 0 {
+		return defaultFloodActions
+	}
+	return settings.Actions
+}
+
+// GetMuteDuration returns duration of mute on flood
+func (settings FloodSettings) GetMuteDuration() time.Duration {
+	if settings.MuteDuration <= 0 {
+		return time.Duration(defaultFloodMuteDuration)
+	}
+	return time.Duration(settings.MuteDuration)
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	couchbase "github.com/couchbase/gocb"
+)
+
+// Formats of greeting text
+const (
+	GreetingFormatPlain    = ""
+	GreetingFormatMarkdown = "markdown"
+	GreetingFormatHTML     = "html"
+)
+
+// Greeting is a template of message sent when member joins or leaves chat,
+// placeholders {name}, {mention}, {chat} and {count} are replaced with values
+type Greeting struct {
+	Text        string `json:"text,omitempty"` // empty means greeting is disabled
+	Format      string `json:"format,omitempty"`
+	DeleteAfter int    `json:"delete_after,omitempty"` // minutes, 0 means message isn't deleted
+}
+
+// AutoDelete main struct for records autodelete:chat_id:message_id, it is a bot message scheduled to delete
+type AutoDelete struct {
+	ChatID    int64  `json:"chat_id"`
+	MessageID int    `json:"message_id"`
+	Deadline  int64  `json:"deadline"`
+	Type      string `json:"type"`
+}
+
+func getAutoDeleteKey(chatID int64, messageID int) string {
+	return fmt.Sprintf("autodelete:%d:%d", chatID, messageID)
+}
+
+// ScheduleDelete stores message to delete at time t
+func ScheduleDelete(chatID int64, messageID int, t time.Time) (err error) {
+	d := &AutoDelete{ChatID: chatID, MessageID: messageID, Deadline: t.Unix(), Type: "autodelete"}
+	_, err = bucket.Upsert(getAutoDeleteKey(chatID, messageID), d, 0)
+	return
+}
+
+// RemoveAutoDelete removes scheduled delete of message, missing record is not an error
+func RemoveAutoDelete(chatID int64, messageID int) (err error) {
+	if _, err = bucket.Remove(getAutoDeleteKey(chatID, messageID), 0); err == couchbase.ErrKeyNotFound {
+		err = nil
+	}
+	return
+}
+
+// GetExpiredAutoDeletes returns messages scheduled to delete before time t
+func GetExpiredAutoDeletes(t time.Time) (deletes []*AutoDelete, err error) {
+	queryStr := fmt.Sprintf("SELECT bot.* FROM %s AS bot WHERE type='autodelete' AND deadline<=%d ORDER BY deadline", bucketName, t.Unix())
+	query := couchbase.NewN1qlQuery(queryStr).Consistency(couchbase.RequestPlus)
+	res, err := bucket.ExecuteN1qlQuery(query, nil)
+	if err != nil {
+		return
+	}
+
+	d := new(AutoDelete)
+	for res.Next(d) {
+		deletes = append(deletes, d)
+		d = new(AutoDelete)
+	}
+	err = res.Close()
+	return
+}
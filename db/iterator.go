@@ -0,0 +1,135 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	couchbase "github.com/couchbase/gocb"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// MessageCursor is a position in chat messages ordered by date and message ID,
+// zero cursor is a position before the first message
+type MessageCursor struct {
+	Date      int64
+	MessageID int
+}
+
+// MessageIterator is a stream of messages from query results
+type MessageIterator struct {
+	res  couchbase.QueryResults
+	msg  *tgbotapi.Message
+	last MessageCursor
+	err  error
+}
+
+// String returns cursor representation for URL
+func (cursor MessageCursor) String() string {
+	return fmt.Sprintf("%d-%d", cursor.Date, cursor.MessageID)
+}
+
+// IsZero returns true for cursor before the first message
+func (cursor MessageCursor) IsZero() bool {
+	return cursor.Date == 0 && cursor.MessageID == 0
+}
+
+// ParseMessageCursor returns cursor from its string representation
+func ParseMessageCursor(s string) (cursor MessageCursor, err error) {
+	if s == "" {
+		return
+	}
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return cursor, fmt.Errorf("Wrong cursor %s", s)
+	}
+	if cursor.Date, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return
+	}
+	cursor.MessageID, err = strconv.Atoi(parts[1])
+	return
+}
+
+// Next prepares the next message, it returns false when messages are over or error occurred,
+// the error is returned by Err and Close
+func (it *MessageIterator) Next() bool {
+	type couchmsg struct {
+		Msg tgbotapi.Message `json:"bot"`
+	}
+
+	it.msg = nil
+	if it.err != nil {
+		return false
+	}
+	msg := couchmsg{}
+	if !it.res.Next(&msg) {
+		return false
+	}
+
+	data, err := json.Marshal(msg.Msg)
+	if err != nil {
+		it.err = fmt.Errorf("marshal message: %s", err)
+		return false
+	}
+	oMsg := new(tgbotapi.Message)
+	if err = json.Unmarshal(data, oMsg); err != nil {
+		it.err = fmt.Errorf("unmarshal message: %s", err)
+		return false
+	}
+	it.msg = oMsg
+	it.last = MessageCursor{Date: int64(oMsg.Date), MessageID: oMsg.MessageID}
+	return true
+}
+
+// Message returns current message
+func (it *MessageIterator) Message() *tgbotapi.Message {
+	return it.msg
+}
+
+// Cursor returns position after current message
+func (it *MessageIterator) Cursor() MessageCursor {
+	return it.last
+}
+
+// Err returns error of iteration
+func (it *MessageIterator) Err() error {
+	return it.err
+}
+
+// Close closes query results, it must be called after iteration
+func (it *MessageIterator) Close() error {
+	if err := it.res.Close(); err != nil && it.err == nil {
+		it.err = err
+	}
+	return it.err
+}
+
+// IterateMessages returns messages stream of chat between beginTime and endTime after cursor,
+// zero times means without limits by date and zero limit means all messages
+func IterateMessages(chatID int64, beginTime, endTime time.Time, after MessageCursor, limit int) (it *MessageIterator, err error) {
+	var where string
+	if !beginTime.IsZero() {
+		where += fmt.Sprintf(" AND date >= %d", beginTime.Unix())
+	}
+	if !endTime.IsZero() {
+		where += fmt.Sprintf(" AND date <= %d", endTime.Unix())
+	}
+	if !after.IsZero() {
+		where += fmt.Sprintf(" AND (date > %d OR (date = %d AND message_id > %d))", after.Date, after.Date, after.MessageID)
+	}
+	var limitStr string
+	if limit > 0 {
+		limitStr = fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	queryStr := fmt.Sprintf("SELECT * FROM %s AS bot WHERE type='message' AND chat.id=%d%s ORDER BY date, message_id%s", bucketName, chatID, where, limitStr)
+	query := couchbase.NewN1qlQuery(queryStr)
+	res, err := bucket.ExecuteN1qlQuery(query, nil)
+	if err != nil {
+		return
+	}
+	it = &MessageIterator{res: res}
+	return
+}
@@ -0,0 +1,49 @@
+package db
+
+import "testing"
+
+func TestMessageCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		cursor MessageCursor
+		str    string
+	}{
+		{MessageCursor{}, "0-0"},
+		{MessageCursor{Date: 1520510400, MessageID: 1}, "1520510400-1"},
+		{MessageCursor{Date: 1520510400, MessageID: 123456}, "1520510400-123456"},
+		{MessageCursor{Date: 1, MessageID: 0}, "1-0"},
+	}
+	for _, tt := range tests {
+		if got := tt.cursor.String(); got != tt.str {
+			t.Errorf("%+v.String() = %q, want %q", tt.cursor, got, tt.str)
+		}
+		cursor, err := ParseMessageCursor(tt.str)
+		if err != nil || cursor != tt.cursor {
+			t.Errorf("ParseMessageCursor(%q) = %+v, %v, want %+v", tt.str, cursor, err, tt.cursor)
+		}
+	}
+}
+
+func TestParseMessageCursorEmpty(t *testing.T) {
+	cursor, err := ParseMessageCursor("")
+	if err != nil || !cursor.IsZero() {
+		t.Fatalf(`ParseMessageCursor("") = %+v, %v, want zero cursor`, cursor, err)
+	}
+}
+
+func TestParseMessageCursorInvalid(t *testing.T) {
+	for _, str := range []string{
+		"abc",
+		"1520510400",
+		"1520510400-",
+		"-1",
+		"1520510400-x",
+		"x-1",
+		"1-2-3",
+		"1.5-2",
+		" 1-2",
+	} {
+		if cursor, err := ParseMessageCursor(str); err == nil {
+			t.Errorf("ParseMessageCursor(%q) = %+v, want error", str, cursor)
+		}
+	}
+}
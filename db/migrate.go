@@ -0,0 +1,173 @@
+package db
+
+import (
+	"fmt"
+	"log"
+
+	couchbase "github.com/couchbase/gocb"
+)
+
+// WarnLevel main struct for records warnlevel:chat_id:id, warning counters are replaced by warning records
+type WarnLevel struct {
+	ID             int    `json:"user_id"`
+	ChatID         int64  `json:"chat_id"`
+	Level          int    `json:"level"`
+	FirstWarningID uint64 `json:"first_warning_id,omitempty"` // IDs of migrated warnings are reserved before adding them
+	Type           string `json:"type"`
+}
+
+// legacyLevel is a warn or cens level record of global per user format:
+// warnlevel:id and censlevel:year:id
+type legacyLevel struct {
+	Key   string `json:"key"`
+	ID    int    `json:"user_id"`
+	Level int    `json:"level"`
+	Year  int    `json:"year"`
+}
+
+// migrateLevels copies legacy global warn and cens levels to every chat where user has posted
+// and removes legacy records
+func migrateLevels() {
+	queryStr := fmt.Sprintf("SELECT META(bot).id AS key, bot.* FROM %s AS bot WHERE (META(bot).id LIKE 'warnlevel:%%' OR META(bot).id LIKE 'censlevel:%%') AND bot.chat_id IS MISSING", bucketName)
+	query := couchbase.NewN1qlQuery(queryStr)
+	res, err := bucket.ExecuteN1qlQuery(query, nil)
+	if err != nil {
+		log.Printf("Error in query legacy levels: %s", err)
+		return
+	}
+
+	var levels []legacyLevel
+	level := legacyLevel{}
+	for res.Next(&level) {
+		levels = append(levels, level)
+		level = legacyLevel{}
+	}
+	if err = res.Close(); err != nil {
+		log.Printf("Error in query legacy levels: %s", err)
+		return
+	}
+
+	for _, level := range levels {
+		if err = migrateLevel(level); err != nil {
+			log.Printf("Error in migrate level %s: %s", level.Key, err)
+			continue
+		}
+		log.Printf("Level %s migrated to chat scoped records", level.Key)
+	}
+}
+
+func migrateLevel(level legacyLevel) (err error) {
+	chats, err := getUserChats(level.ID)
+	if err != nil {
+		return
+	}
+
+	for _, chatID := range chats {
+		var (
+			key string
+			doc interface{}
+		)
+		if level.Year != 0 {
+			key = getCensLevelKey(chatID, level.Year, level.ID)
+			doc = &CensLevel{ID: level.ID, ChatID: chatID, Level: level.Level, Year: level.Year, Type: "censlevel"}
+		} else {
+			key = getWarnLevelKey(chatID, level.ID)
+			doc = &WarnLevel{ID: level.ID, ChatID: chatID, Level: level.Level, Type: "warnlevel"}
+		}
+		// chat scoped record is newer than legacy one
+		if _, err = bucket.Insert(key, doc, 0); err != nil && err != couchbase.ErrKeyExists {
+			return
+		}
+	}
+
+	_, err = bucket.Remove(level.Key, 0)
+	return
+}
+
+func getWarnLevelKey(chatID int64, userID int) string {
+	return fmt.Sprintf("warnlevel:%d:%d", chatID, userID)
+}
+
+// migrateWarnLevels replaces warning counters of chats with warning records without reason
+func migrateWarnLevels() {
+	queryStr := fmt.Sprintf("SELECT bot.* FROM %s AS bot WHERE type='warnlevel'", bucketName)
+	query := couchbase.NewN1qlQuery(queryStr).Consistency(couchbase.RequestPlus)
+	res, err := bucket.ExecuteN1qlQuery(query, nil)
+	if err != nil {
+		log.Printf("Error in query warn levels: %s", err)
+		return
+	}
+
+	var levels []WarnLevel
+	level := WarnLevel{}
+	for res.Next(&level) {
+		levels = append(levels, level)
+		level = WarnLevel{}
+	}
+	if err = res.Close(); err != nil {
+		log.Printf("Error in query warn levels: %s", err)
+		return
+	}
+
+	for _, level := range levels {
+		key := getWarnLevelKey(level.ChatID, level.ID)
+		if err = migrateWarnLevel(key); err != nil {
+			log.Printf("Error in migrate warn level %s: %s", key, err)
+			continue
+		}
+		log.Printf("Warn level %s migrated to %d warnings", key, level.Level)
+	}
+}
+
+// migrateWarnLevel replaces warning counter with warnings, IDs of warnings are reserved in counter record first,
+// so interrupted migration is resumed without duplicated warnings
+func migrateWarnLevel(key string) (err error) {
+	level := WarnLevel{}
+	cas, err := bucket.Get(key, &level)
+	if err != nil {
+		return
+	}
+	if level.Level > 0 && level.FirstWarningID == 0 {
+		last, _, err := bucket.Counter(getWarningSeqKey(level.ChatID), int64(level.Level), int64(level.Level), 0)
+		if err != nil {
+			return err
+		}
+		level.FirstWarningID = last - uint64(level.Level) + 1
+		if _, err = bucket.Replace(key, &level, cas, 0); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < level.Level; i++ {
+		w := &Warning{
+			ID:     level.FirstWarningID + uint64(i),
+			ChatID: level.ChatID,
+			UserID: level.ID,
+			Reason: "перенесено из счетчика предупреждений",
+		}
+		// warning is already added by interrupted migration
+		if err = insertWarning(w); err != nil && err != couchbase.ErrKeyExists {
+			return
+		}
+	}
+
+	_, err = bucket.Remove(key, 0)
+	return
+}
+
+// getUserChats returns IDs of chats where user has posted
+func getUserChats(userID int) (chats []int64, err error) {
+	queryStr := fmt.Sprintf("SELECT DISTINCT RAW chat.id FROM %s WHERE type='message' AND `from`.id=%d", bucketName, userID)
+	query := couchbase.NewN1qlQuery(queryStr)
+	res, err := bucket.ExecuteN1qlQuery(query, nil)
+	if err != nil {
+		return
+	}
+
+	var chatID int64
+	for res.Next(&chatID) {
+		chats = append(chats, chatID)
+	}
+	err = res.Close()
+	return
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	couchbase "github.com/couchbase/gocb"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// ModeratedMessage main struct for records moderated:chat_id:message_id,
+// message removed from chat by moderation stays in archive and is shown to archive admins only
+type ModeratedMessage struct {
+	ChatID      int64  `json:"chat_id"`
+	MessageID   int    `json:"message_id"`
+	MessageDate int64  `json:"message_date"`
+	Reason      string `json:"reason"`
+	IssuerID    int    `json:"issuer_id"` // zero means the bot
+	Date        int64  `json:"date"`
+	Type        string `json:"type"`
+}
+
+func getModeratedMessageKey(chatID int64, messageID int) string {
+	return fmt.Sprintf("moderated:%d:%d", chatID, messageID)
+}
+
+// MarkMessageRemoved marks message as removed by moderation with reason
+func MarkMessageRemoved(msg *tgbotapi.Message, reason string, issuerID int) (err error) {
+	m := &ModeratedMessage{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.MessageID,
+		MessageDate: int64(msg.Date),
+		Reason:      reason,
+		IssuerID:    issuerID,
+		Date:        time.Now().Unix(),
+		Type:        "moderated",
+	}
+	if _, err = bucket.Upsert(getModeratedMessageKey(m.ChatID, m.MessageID), m, 0); err != nil {
+		return
+	}
+	Events.Publish(msg.Chat.ID, MessageEvent{Type: EventRemovedMessage, Message: msg})
+	return
+}
+
+// GetModeratedMessage returns moderation mark of message, nil is returned if message isn't removed
+func GetModeratedMessage(chatID int64, messageID int) (m *ModeratedMessage, err error) {
+	m = new(ModeratedMessage)
+	if _, err = bucket.Get(getModeratedMessageKey(chatID, messageID), m); err == couchbase.ErrKeyNotFound {
+		return nil, nil
+	}
+	return
+}
+
+// GetModeratedMessages returns moderation marks of chat messages between beginTime and endTime by message ID,
+// zero times means without limits by date
+func GetModeratedMessages(chatID int64, beginTime, endTime time.Time) (marks map[int]*ModeratedMessage, err error) {
+	var where string
+	if !beginTime.IsZero() {
+		where += fmt.Sprintf(" AND message_date >= %d", beginTime.Unix())
+	}
+	if !endTime.IsZero() {
+		where += fmt.Sprintf(" AND message_date <= %d", endTime.Unix())
+	}
+	queryStr := fmt.Sprintf("SELECT bot.* FROM %s AS bot WHERE type='moderated' AND chat_id=%d%s", bucketName, chatID, where)
+	query := couchbase.NewN1qlQuery(queryStr)
+	res, err := bucket.ExecuteN1qlQuery(query, nil)
+	if err != nil {
+		return
+	}
+
+	marks = make(map[int]*ModeratedMessage)
+	m := new(ModeratedMessage)
+	for res.Next(m) {
+		marks[m.MessageID] = m
+		m = new(ModeratedMessage)
+	}
+	err = res.Close()
+	return
+}
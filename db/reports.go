@@ -0,0 +1,157 @@
+package db
+
+import (
+	"fmt"
+
+	couchbase "github.com/couchbase/gocb"
+)
+
+// Report statuses, handled report has status of action taken by admin
+const (
+	ReportOpen    = "open"
+	ReportWarn    = "warn"
+	ReportMute    = "mute"
+	ReportBan     = "ban"
+	ReportDismiss = "dismiss"
+)
+
+// ReportSettings settings of member reports in chat
+type ReportSettings struct {
+	ChatID int64 `json:"chat_id,omitempty"` // admin chat for reports, 0 means reports are sent to admins privately
+}
+
+// ReportNotice is a message with report sent to admin chat or to admin privately
+type ReportNotice struct {
+	ChatID    int64 `json:"chat_id"`
+	MessageID int   `json:"message_id"`
+}
+
+// Report main struct for records report:chat_id:message_id, it is a report of member on message
+type Report struct {
+	ChatID     int64          `json:"chat_id"`
+	Chat       string         `json:"chat"`
+	MessageID  int            `json:"message_id"` // reported message
+	UserID     int            `json:"user_id"`    // author of reported message
+	User       string         `json:"user"`
+	FirstName  string         `json:"first_name"` // name of author to restrict user missing in database
+	LastName   string         `json:"last_name,omitempty"`
+	UserName   string         `json:"username,omitempty"`
+	ReporterID int            `json:"reporter_id"`
+	Reporter   string         `json:"reporter"`
+	Reason     string         `json:"reason,omitempty"`
+	Text       string         `json:"text,omitempty"`
+	Link       string         `json:"link,omitempty"`
+	Notices    []ReportNotice `json:"notices,omitempty"`
+	Status     string         `json:"status"`
+	HandlerID  int            `json:"handler_id,omitempty"`
+	Handler    string         `json:"handler,omitempty"`
+	Handled    int64          `json:"handled,omitempty"`
+	Date       int64          `json:"date"`
+	Type       string         `json:"type"`
+}
+
+func getReportKey(chatID int64, messageID int) string {
+	return fmt.Sprintf("report:%d:%d", chatID, messageID)
+}
+
+// AddReport stores new open report, handled report on the same message is replaced,
+// ok is false if message already has open report
+func AddReport(r *Report) (ok bool, err error) {
+	r.Status, r.Type = ReportOpen, "report"
+	key := getReportKey(r.ChatID, r.MessageID)
+
+	for i := 0; i < casRetries; i++ {
+		if _, err = bucket.Insert(key, r, 0); err != couchbase.ErrKeyExists {
+			return err == nil, err
+		}
+
+		old := new(Report)
+		var cas couchbase.Cas
+		if cas, err = bucket.Get(key, old); err == couchbase.ErrKeyNotFound {
+			// removed meanwhile, try to insert again
+			continue
+		} else if err != nil {
+			return
+		}
+		if old.Status == ReportOpen {
+			return false, nil
+		}
+		if _, err = bucket.Replace(key, r, cas, 0); err == couchbase.ErrKeyExists || err == couchbase.ErrKeyNotFound {
+			// changed or removed by someone else, try again
+			continue
+		}
+		return err == nil, err
+	}
+	return false, fmt.Errorf("Report %d in chat %d is busy", r.MessageID, r.ChatID)
+}
+
+// GetReport returns report on message, nil is returned if message isn't reported
+func GetReport(chatID int64, messageID int) (r *Report, err error) {
+	r = new(Report)
+	if _, err = bucket.Get(getReportKey(chatID, messageID), r); err == couchbase.ErrKeyNotFound {
+		return nil, nil
+	}
+	return
+}
+
+// RemoveReport removes report, missing record is not an error
+func RemoveReport(chatID int64, messageID int) (err error) {
+	if _, err = bucket.Remove(getReportKey(chatID, messageID), 0); err == couchbase.ErrKeyNotFound {
+		err = nil
+	}
+	return
+}
+
+// AddReportNotices appends messages with report sent to admins
+func AddReportNotices(chatID int64, messageID int, notices []ReportNotice) (err error) {
+	_, _, err = updateReport(chatID, messageID, func(r *Report) bool {
+		r.Notices = append(r.Notices, notices...)
+		return true
+	})
+	return
+}
+
+// HandleReport sets status of open report and records admin who handled it,
+// ok is false if report is missing or already handled, the report is returned anyway
+func HandleReport(chatID int64, messageID int, status string, handlerID int, handler string, date int64) (r *Report, ok bool, err error) {
+	return updateReport(chatID, messageID, func(r *Report) bool {
+		if r.Status != ReportOpen {
+			return false
+		}
+		r.Status, r.HandlerID, r.Handler, r.Handled = status, handlerID, handler, date
+		return true
+	})
+}
+
+// ReopenReport returns handled report to open status, it is used if action of admin failed
+func ReopenReport(chatID int64, messageID int) (err error) {
+	_, _, err = updateReport(chatID, messageID, func(r *Report) bool {
+		r.Status, r.HandlerID, r.Handler, r.Handled = ReportOpen, 0, "", 0
+		return true
+	})
+	return
+}
+
+// updateReport changes stored report by update function, report isn't stored if update returns false
+func updateReport(chatID int64, messageID int, update func(r *Report) bool) (r *Report, updated bool, err error) {
+	key := getReportKey(chatID, messageID)
+
+	for i := 0; i < casRetries; i++ {
+		r = new(Report)
+		var cas couchbase.Cas
+		if cas, err = bucket.Get(key, r); err == couchbase.ErrKeyNotFound {
+			return nil, false, nil
+		} else if err != nil {
+			return
+		}
+		if !update(r) {
+			return r, false, nil
+		}
+		if _, err = bucket.Replace(key, r, cas, 0); err == couchbase.ErrKeyExists {
+			// changed by someone else, try again
+			continue
+		}
+		return r, err == nil, err
+	}
+	return nil, false, fmt.Errorf("Report %d in chat %d is busy", messageID, chatID)
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	couchbase "github.com/couchbase/gocb"
+)
+
+// Restriction kinds, read-only mode is a mute until /unmute by default, Telegram keeps the last of them
+const (
+	RestrictionMute     = "mute"
+	RestrictionReadOnly = "readonly"
+	RestrictionBan      = "ban"
+)
+
+// Restriction main struct for records restriction:chat_id:user_id:kind,
+// records are stored for temporary restrictions only and removed when restriction is lifted
+type Restriction struct {
+	ChatID   int64  `json:"chat_id"`
+	UserID   int    `json:"user_id"`
+	User     string `json:"user"`
+	Kind     string `json:"kind"`
+	Until    int64  `json:"until"`
+	IssuerID int    `json:"issuer_id"`
+	Reason   string `json:"reason"`
+	Date     int64  `json:"date"`
+	Type     string `json:"type"`
+}
+
+func getRestrictionKey(chatID int64, userID int, kind string) string {
+	return fmt.Sprintf("restriction:%d:%d:%s", chatID, userID, kind)
+}
+
+// SaveRestriction stores temporary restriction
+func SaveRestriction(r *Restriction) (err error) {
+	r.Type = "restriction"
+	_, err = bucket.Upsert(getRestrictionKey(r.ChatID, r.UserID, r.Kind), r, 0)
+	return
+}
+
+// RemoveRestriction removes restriction record, missing record is not an error
+func RemoveRestriction(chatID int64, userID int, kind string) (err error) {
+	if _, err = bucket.Remove(getRestrictionKey(chatID, userID, kind), 0); err == couchbase.ErrKeyNotFound {
+		err = nil
+	}
+	return
+}
+
+// GetExpiredRestrictions returns temporary restrictions expired at time t
+func GetExpiredRestrictions(t time.Time) (restrictions []*Restriction, err error) {
+	queryStr := fmt.Sprintf("SELECT bot.* FROM %s AS bot WHERE type='restriction' AND until<=%d ORDER BY until", bucketName, t.Unix())
+	query := couchbase.NewN1qlQuery(queryStr).Consistency(couchbase.RequestPlus)
+	res, err := bucket.ExecuteN1qlQuery(query, nil)
+	if err != nil {
+		return
+	}
+
+	r := new(Restriction)
+	for res.Next(r) {
+		restrictions = append(restrictions, r)
+		r = new(Restriction)
+	}
+	err = res.Close()
+	return
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"fmt"
+
+	couchbase "github.com/couchbase/gocb"
+)
+
+// Chat roles delegated by chat administrators
+const (
+	RoleModerator = "moderator"
+	RoleTrusted   = "trusted"
+)
+
+// ChatRole main struct for records chatrole:chat_id:user_id
+type ChatRole struct {
+	ChatID    int64  `json:"chat_id"`
+	UserID    int    `json:"user_id"`
+	User      string `json:"user"`
+	Role      string `json:"role"`
+	GrantedBy int    `json:"granted_by"`
+	Date      int64  `json:"date"`
+	Type      string `json:"type"`
+}
+
+func getChatRoleKey(chatID int64, userID int) string {
+	return fmt.Sprintf("chatrole:%d:%d", chatID, userID)
+}
+
+// GetChatRole returns role delegated to user in chat, empty role returned if user has no role
+func GetChatRole(chatID int64, userID int) (role *ChatRole, err error) {
+	role = &ChatRole{ChatID: chatID, UserID: userID, Type: "chatrole"}
+	if _, err = bucket.Get(getChatRoleKey(chatID, userID), role); err == couchbase.ErrKeyNotFound {
+		err = nil
+	}
+	return
+}
+
+// SetChatRole stores role delegated to user in chat
+func SetChatRole(role *ChatRole) (err error) {
+	role.Type = "chatrole"
+	_, err = bucket.Upsert(getChatRoleKey(role.ChatID, role.UserID), role, 0)
+	return
+}
+
+// RemoveChatRole removes role delegated to user in chat
+func RemoveChatRole(chatID int64, userID int) (err error) {
+	if _, err = bucket.Remove(getChatRoleKey(chatID, userID), 0); err == couchbase.ErrKeyNotFound {
+		err = nil
+	}
+	return
+}
+
+// GetChatRoles returns roles delegated in chat
+func GetChatRoles(chatID int64) (roles []*ChatRole, err error) {
+	queryStr := fmt.Sprintf("SELECT bot.* FROM %s AS bot WHERE type='chatrole' AND chat_id=%d ORDER BY role, `user`", bucketName, chatID)
+	query := couchbase.NewN1qlQuery(queryStr)
+	res, err := bucket.ExecuteN1qlQuery(query, nil)
+	if err != nil {
+		return
+	}
+
+	role := new(ChatRole)
+	for res.Next(role) {
+		roles = append(roles, role)
+		role = new(ChatRole)
+	}
+	err = res.Close()
+	return
+}
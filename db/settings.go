@@ -0,0 +1,168 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/elemc/gotelegrambot/escalation"
+
+	couchbase "github.com/couchbase/gocb"
+)
+
+var (
+	defaultLocation   = time.Local
+	defaultWarnPolicy = escalation.Policy{Steps: []escalation.Step{{Level: 5, Action: escalation.ActionBan}}}
+	defaultCensPolicy = escalation.Policy{Steps: []escalation.Step{{Level: 6, Action: escalation.ActionBan}}}
+)
+
+// Feed modes for chat feeds
+const (
+	FeedModeMessage = "message"
+	FeedModeDay     = "day"
+)
+
+// ChatSettings main struct for records chatsettings:chat_id
+type ChatSettings struct {
+	ChatID         int64  `json:"chat_id"`
+	Timezone       string `json:"timezone"`
+	Hidden         bool   `json:"hidden"`
+	FeedMode       string `json:"feed_mode"`
+	Language       string `json:"language,omitempty"` // empty means language of users or default
+	WarnExpireDays int    `json:"warn_expire_days"`   // 0 means warnings never expire
+	Type           string `json:"type"`
+
+	WarnPolicy *escalation.Policy `json:"warn_policy,omitempty"`
+	CensPolicy *escalation.Policy `json:"cens_policy,omitempty"`
+
+	Cens   CensSettings   `json:"cens"`
+	Flood  FloodSettings  `json:"flood"`
+	Spam   SpamSettings   `json:"spam"`
+	Report ReportSettings `json:"report"`
+
+	Captcha CaptchaSettings `json:"captcha"`
+	Welcome Greeting        `json:"welcome"`
+	Goodbye Greeting        `json:"goodbye"`
+}
+
+// SetDefaultLocation sets timezone for chats without own timezone setting
+func SetDefaultLocation(name string) (err error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return
+	}
+	defaultLocation = loc
+	return
+}
+
+// GetDefaultLocation returns timezone for chats without own timezone setting
+func GetDefaultLocation() *time.Location {
+	return defaultLocation
+}
+
+func getChatSettingsKey(chatID int64) string {
+	return fmt.Sprintf("chatsettings:%d", chatID)
+}
+
+// GetChatSettings returns settings for chat, default settings returned if chat has no settings
+func GetChatSettings(chatID int64) (settings *ChatSettings, err error) {
+	settings = &ChatSettings{ChatID: chatID, Type: "chatsettings"}
+	if _, err = bucket.Get(getChatSettingsKey(chatID), settings); err == couchbase.ErrKeyNotFound {
+		err = nil
+	}
+	return
+}
+
+// SetDefaultPolicies sets escalation policies for chats without own policies
+func SetDefaultPolicies(warn, cens escalation.Policy) (err error) {
+	if err = warn.Validate(); err != nil {
+		return
+	}
+	if err = cens.Validate(); err != nil {
+		return
+	}
+	defaultWarnPolicy, defaultCensPolicy = warn, cens
+	return
+}
+
+// GetWarnPolicy returns escalation policy for warnings counter
+func (settings *ChatSettings) GetWarnPolicy() escalation.Policy {
+	if settings.WarnPolicy != nil {
+		return *settings.WarnPolicy
+	}
+	return defaultWarnPolicy
+}
+
+// GetCensPolicy returns escalation policy for cens words counter
+func (settings *ChatSettings) GetCensPolicy() escalation.Policy {
+	if settings.CensPolicy != nil {
+		return *settings.CensPolicy
+	}
+	return defaultCensPolicy
+}
+
+// GetWarnExpire returns expiration time for warning issued at t, zero time means warning never expires
+func (settings *ChatSettings) GetWarnExpire(t time.Time) time.Time {
+	if settings.WarnExpireDays <= 0 {
+		return time.Time{}
+	}
+	return t.AddDate(0, 0, settings.WarnExpireDays)
+}
+
+// SaveChatSettings stores settings for chat
+func SaveChatSettings(settings *ChatSettings) (err error) {
+	settings.Type = "chatsettings"
+	_, err = bucket.Upsert(getChatSettingsKey(settings.ChatID), settings, 0)
+	return
+}
+
+// GetChatLocation returns timezone for chat
+func GetChatLocation(chatID int64) *time.Location {
+	settings, err := GetChatSettings(chatID)
+	if err != nil {
+		log.Printf("Error in GetChatSettings for chat %d: %s", chatID, err)
+		return defaultLocation
+	}
+	if settings.Timezone == "" {
+		return defaultLocation
+	}
+	loc, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		log.Printf("Error in load timezone [%s] for chat %d: %s", settings.Timezone, chatID, err)
+		return defaultLocation
+	}
+	return loc
+}
+
+// SetChatTimezone sets timezone for chat and rebuilds chat date index with it,
+// empty name resets chat timezone to default
+func SetChatTimezone(chatID int64, name string) (loc *time.Location, err error) {
+	loc = defaultLocation
+	if name != "" {
+		if loc, err = time.LoadLocation(name); err != nil {
+			return
+		}
+	}
+
+	settings, err := GetChatSettings(chatID)
+	if err != nil {
+		return
+	}
+	settings.Timezone = name
+	if err = SaveChatSettings(settings); err != nil {
+		return
+	}
+
+	_, err = rebuildDateIndex(chatID)
+	return
+}
+
+// ChatIsHidden returns true if chat is hidden from web archive, chat is hidden if settings are unavailable
+func ChatIsHidden(chatID int64) bool {
+	settings, err := GetChatSettings(chatID)
+	if err != nil {
+		log.Printf("Error in GetChatSettings for chat %d: %s", chatID, err)
+		return true
+	}
+	return settings.Hidden
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elemc/gotelegrambot/escalation"
+
+	couchbase "github.com/couchbase/gocb"
+)
+
+// Kinds of content blocked for new members
+const (
+	SpamKindLink    = "link"    // links in text
+	SpamKindInvite  = "invite"  // t.me links and invites
+	SpamKindForward = "forward" // posts forwarded from channels
+	SpamKindMedia   = "media"   // photos, videos, documents and other media
+)
+
+// Actions on blocked content
+const (
+	SpamActionDelete = "delete"
+	SpamActionWarn   = "warn"
+	SpamActionMute   = "mute"
+	SpamActionBan    = "ban"
+)
+
+// Default spam settings
+const (
+	defaultSpamMinMessages  = 5
+	defaultSpamMinHours     = 24
+	defaultSpamMuteDuration = escalation.Duration(24 * time.Hour)
+)
+
+var (
+	// SpamKinds are all kinds of blocked content
+	SpamKinds = []string{SpamKindInvite, SpamKindLink, SpamKindForward, SpamKindMedia}
+
+	defaultSpamActions = []string{SpamActionDelete}
+)
+
+// SpamSettings settings of spam filter in chat, user is new member if the user has less than MinMessages messages
+// in chat or the first message of the user in chat is younger than MinHours hours
+type SpamSettings struct {
+	Enabled      bool                `json:"enabled"`
+	MinMessages  int                 `json:"min_messages,omitempty"`  // 0 means default
+	MinHours     int                 `json:"min_hours,omitempty"`     // 0 means default
+	Kinds        []string            `json:"kinds,omitempty"`         // empty means all kinds
+	Actions      []string            `json:"actions,omitempty"`       // empty means default
+	MuteDuration escalation.Duration `json:"mute_duration,omitempty"` // 0 means default
+}
+
+// SpamLogEntry main struct for records spamlog:chat_id:id, it is a log of content blocked by spam filter
+type SpamLogEntry struct {
+	ID        uint64   `json:"id"`
+	ChatID    int64    `json:"chat_id"`
+	UserID    int      `json:"user_id"`
+	User      string   `json:"user"`
+	MessageID int      `json:"message_id"`
+	Kind      string   `json:"kind"`
+	Text      string   `json:"text"`
+	Actions   []string `json:"actions"`
+	Date      int64    `json:"date"`
+	Type      string   `json:"type"`
+}
+
+// GetMinMessages returns minimal count of messages of old member
+func (settings SpamSettings) GetMinMessages() int {
+	if settings.MinMessages <= 0 {
+		return defaultSpamMinMessages
+	}
+	return settings.MinMessages
+}
+
+// GetMinAge returns minimal age of old member in chat
+func (settings SpamSettings) GetMinAge() time.Duration {
+	if settings.MinHours <= 0 {
+		return defaultSpamMinHours * time.Hour
+	}
+	return time.Duration(settings.MinHours) * time.Hour
+}
+
+// GetKinds returns kinds of blocked content
+func (settings SpamSettings) GetKinds() []string {
+	if len(settings.Kinds) == 0 {
+		return SpamKinds
+	}
+	return settings.Kinds
+}
+
+// GetActions returns actions on blocked content
+func (settings SpamSettings) GetActions() []string {
+	if len(settings.Actions) == 0 {
+		return defaultSpamActions
+	}
+	return settings.Actions
+}
+
+// GetMuteDuration returns duration of mute for blocked content
+func (settings SpamSettings) GetMuteDuration() time.Duration {
+	if settings.MuteDuration <= 0 {
+		return time.Duration(defaultSpamMuteDuration)
+	}
+	return time.Duration(settings.MuteDuration)
+}
+
+// GetUserHistory returns count of stored messages of user in chat and date of the first of them,
+// first is zero if user has no messages
+func GetUserHistory(chatID int64, userID int) (count int, first time.Time, err error) {
+	queryStr := fmt.Sprintf("SELECT COUNT(*) AS `count`, MIN(date) AS `first` FROM %s WHERE type='message' AND chat.id=%d AND `from`.id=%d",
+		bucketName, chatID, userID)
+	query := couchbase.NewN1qlQuery(queryStr)
+	res, err := bucket.ExecuteN1qlQuery(query, nil)
+	if err != nil {
+		return
+	}
+
+	var row struct {
+		Count int   `json:"count"`
+		First int64 `json:"first"`
+	}
+	if res.Next(&row) {
+		count = row.Count
+		if row.First > 0 {
+			first = time.Unix(row.First, 0)
+		}
+	}
+	err = res.Close()
+	return
+}
+
+// AddSpamLogEntry stores new entry of spam log, ID of entry is assigned by sequence of chat
+func AddSpamLogEntry(entry *SpamLogEntry) (err error) {
+	id, _, err := bucket.Counter(fmt.Sprintf("spamlogseq:%d", entry.ChatID), 1, 1, 0)
+	if err != nil {
+		return
+	}
+	entry.ID = id
+	entry.Type = "spamlog"
+	if entry.Date == 0 {
+		entry.Date = time.Now().Unix()
+	}
+	_, err = bucket.Insert(fmt.Sprintf("spamlog:%d:%d", entry.ChatID, entry.ID), entry, 0)
+	return
+}
+
+// GetSpamLog returns the last limit entries of spam log of chat, the newest first
+func GetSpamLog(chatID int64, limit int) (entries []*SpamLogEntry, err error) {
+	queryStr := fmt.Sprintf("SELECT bot.* FROM %s AS bot WHERE type='spamlog' AND chat_id=%d ORDER BY id DESC LIMIT %d",
+		bucketName, chatID, limit)
+	query := couchbase.NewN1qlQuery(queryStr)
+	res, err := bucket.ExecuteN1qlQuery(query, nil)
+	if err != nil {
+		return
+	}
+
+	entry := new(SpamLogEntry)
+	for res.Next(entry) {
+		entries = append(entries, entry)
+		entry = new(SpamLogEntry)
+	}
+	err = res.Close()
+	return
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	couchbase "github.com/couchbase/gocb"
+)
+
+// Warning main struct for records warning:chat_id:id
+type Warning struct {
+	ID        uint64 `json:"id"`
+	ChatID    int64  `json:"chat_id"`
+	UserID    int    `json:"user_id"`
+	User      string `json:"user"`
+	IssuerID  int    `json:"issuer_id"`
+	Issuer    string `json:"issuer"`
+	Reason    string `json:"reason"`
+	MessageID int    `json:"message_id"`
+	Link      string `json:"link"`
+	Date      int64  `json:"date"`
+	Expire    int64  `json:"expire"` // 0 means warning never expires
+	Type      string `json:"type"`
+}
+
+func getWarningKey(chatID int64, id uint64) string {
+	return fmt.Sprintf("warning:%d:%d", chatID, id)
+}
+
+// Expired returns true if warning is expired at time t
+func (w *Warning) Expired(t time.Time) bool {
+	return w.Expire != 0 && w.Expire <= t.Unix()
+}
+
+func getWarningSeqKey(chatID int64) string {
+	return fmt.Sprintf("warningseq:%d", chatID)
+}
+
+// AddWarning stores new warning, ID of warning is assigned by sequence of chat
+func AddWarning(w *Warning) (err error) {
+	if w.ID, _, err = bucket.Counter(getWarningSeqKey(w.ChatID), 1, 1, 0); err != nil {
+		return
+	}
+	return insertWarning(w)
+}
+
+// insertWarning stores warning with assigned ID
+func insertWarning(w *Warning) (err error) {
+	w.Type = "warning"
+	if w.Date == 0 {
+		w.Date = time.Now().Unix()
+	}
+
+	// expired warnings are removed by couchbase, absolute unix time is used as expiry
+	_, err = bucket.Insert(getWarningKey(w.ChatID, w.ID), w, uint32(w.Expire))
+	return
+}
+
+// GetWarning returns warning of chat by ID
+func GetWarning(chatID int64, id uint64) (w *Warning, err error) {
+	w = new(Warning)
+	if _, err = bucket.Get(getWarningKey(chatID, id), w); err != nil {
+		return nil, err
+	}
+	if w.Expired(time.Now()) {
+		return nil, couchbase.ErrKeyNotFound
+	}
+	return
+}
+
+// GetWarnings returns active warnings of user in chat ordered by date
+func GetWarnings(chatID int64, userID int) (warnings []*Warning, err error) {
+	queryStr := fmt.Sprintf("SELECT bot.* FROM %s AS bot WHERE type='warning' AND chat_id=%d AND user_id=%d AND (expire=0 OR expire>%d) ORDER BY date, id",
+		bucketName, chatID, userID, time.Now().Unix())
+	// warning may be just added
+	query := couchbase.NewN1qlQuery(queryStr).Consistency(couchbase.RequestPlus)
+	res, err := bucket.ExecuteN1qlQuery(query, nil)
+	if err != nil {
+		return
+	}
+
+	w := new(Warning)
+	for res.Next(w) {
+		warnings = append(warnings, w)
+		w = new(Warning)
+	}
+	err = res.Close()
+	return
+}
+
+// RemoveWarning removes warning of chat by ID
+func RemoveWarning(chatID int64, id uint64) (err error) {
+	_, err = bucket.Remove(getWarningKey(chatID, id), 0)
+	return
+}
+
+// ClearWarnings removes all warnings of user in chat
+func ClearWarnings(chatID int64, userID int) (err error) {
+	queryStr := fmt.Sprintf("DELETE FROM %s WHERE type='warning' AND chat_id=%d AND user_id=%d", bucketName, chatID, userID)
+	query := couchbase.NewN1qlQuery(queryStr).Consistency(couchbase.RequestPlus)
+	res, err := bucket.ExecuteN1qlQuery(query, nil)
+	if err != nil {
+		return
+	}
+	err = res.Close()
+	return
+}
@@ -0,0 +1,234 @@
+// Package escalation describes sanctions applied when moderation counters grow,
+// it doesn't depend on Telegram and database
+package escalation
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Actions of policy steps
+const (
+	ActionWarn = "warn" // warning message only
+	ActionMute = "mute"
+	ActionBan  = "ban"
+)
+
+var (
+	durationRegexp     = regexp.MustCompile(`^(\d+[mhdw])+$`)
+	durationPartRegexp = regexp.MustCompile(`(\d+)([mhdw])`)
+	durationUnits      = map[string]time.Duration{
+		"m": time.Minute,
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+)
+
+// Duration is a duration in format like 30m, 2d or 1d12h
+type Duration time.Duration
+
+// Step is an action applied when counter reaches level
+type Step struct {
+	Level    int      `json:"level"`
+	Action   string   `json:"action"`
+	Duration Duration `json:"duration,omitempty"` // zero duration of ban means ban forever
+}
+
+// Policy is a list of steps for counter ordered by level
+type Policy struct {
+	Steps []Step `json:"steps"`
+}
+
+type stepsByLevel []Step
+
+func (steps stepsByLevel) Len() int           { return len(steps) }
+func (steps stepsByLevel) Less(i, j int) bool { return steps[i].Level < steps[j].Level }
+func (steps stepsByLevel) Swap(i, j int)      { steps[i], steps[j] = steps[j], steps[i] }
+
+// IsDuration returns true if string looks like duration
+func IsDuration(str string) bool {
+	return durationRegexp.MatchString(strings.ToLower(str))
+}
+
+// ParseDuration parses durations like 30m, 2d or 1d12h
+func ParseDuration(str string) (d Duration, err error) {
+	str = strings.ToLower(str)
+	if !durationRegexp.MatchString(str) {
+		return 0, fmt.Errorf("invalid duration %q", str)
+	}
+	var result time.Duration
+	for _, part := range durationPartRegexp.FindAllStringSubmatch(str, -1) {
+		n, err := strconv.Atoi(part[1])
+		if err != nil {
+			return 0, err
+		}
+		result += time.Duration(n) * durationUnits[part[2]]
+	}
+	if result < time.Minute {
+		return 0, fmt.Errorf("duration %q is less than a minute", str)
+	}
+	return Duration(result), nil
+}
+
+// String returns duration in format of ParseDuration
+func (d Duration) String() string {
+	if d == 0 {
+		return "0m"
+	}
+	var (
+		result string
+		rest   = time.Duration(d)
+	)
+	for _, unit := range []string{"w", "d", "h", "m"} {
+		if n := rest / durationUnits[unit]; n > 0 {
+			result += fmt.Sprintf("%d%s", n, unit)
+			rest -= n * durationUnits[unit]
+		}
+	}
+	if result == "" {
+		return "0m"
+	}
+	return result
+}
+
+// MarshalJSON encodes duration as string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes duration from string
+func (d *Duration) UnmarshalJSON(data []byte) (err error) {
+	var str string
+	if err = json.Unmarshal(data, &str); err != nil {
+		return
+	}
+	if str == "" || str == "0m" {
+		*d = 0
+		return
+	}
+	*d, err = ParseDuration(str)
+	return
+}
+
+// String returns step in format of ParseStep
+func (step Step) String() string {
+	if step.Duration == 0 {
+		return fmt.Sprintf("%d:%s", step.Level, step.Action)
+	}
+	return fmt.Sprintf("%d:%s:%s", step.Level, step.Action, step.Duration)
+}
+
+// ParseStep parses step in format level:action[:duration], e.g. 3:warn, 5:mute:1h or 8:ban
+func ParseStep(str string) (step Step, err error) {
+	parts := strings.Split(strings.ToLower(str), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return step, fmt.Errorf("invalid step %q, format is level:action[:duration]", str)
+	}
+	if step.Level, err = strconv.Atoi(parts[0]); err != nil || step.Level <= 0 {
+		return step, fmt.Errorf("invalid level of step %q", str)
+	}
+	step.Action = parts[1]
+	if len(parts) == 3 {
+		if step.Duration, err = ParseDuration(parts[2]); err != nil {
+			return
+		}
+	}
+	err = step.Validate()
+	return
+}
+
+// Validate returns error if step is invalid
+func (step Step) Validate() error {
+	if step.Level <= 0 {
+		return fmt.Errorf("level of step %s must be positive", step)
+	}
+	switch step.Action {
+	case ActionWarn:
+		if step.Duration != 0 {
+			return fmt.Errorf("step %s: action %s has no duration", step, step.Action)
+		}
+	case ActionMute:
+		if step.Duration == 0 {
+			return fmt.Errorf("step %s: action %s requires duration", step, step.Action)
+		}
+	case ActionBan:
+	default:
+		return fmt.Errorf("step %s: unknown action %q", step, step.Action)
+	}
+	return nil
+}
+
+// ParsePolicy parses policy from list of steps
+func ParsePolicy(steps []string) (policy Policy, err error) {
+	for _, str := range steps {
+		step, err := ParseStep(str)
+		if err != nil {
+			return Policy{}, err
+		}
+		policy.Steps = append(policy.Steps, step)
+	}
+	sort.Sort(stepsByLevel(policy.Steps))
+	err = policy.Validate()
+	return
+}
+
+// Validate returns error if policy is invalid
+func (policy Policy) Validate() error {
+	for i, step := range policy.Steps {
+		if err := step.Validate(); err != nil {
+			return err
+		}
+		if i > 0 && policy.Steps[i-1].Level >= step.Level {
+			return fmt.Errorf("levels of steps must be unique and ascending: %s", policy)
+		}
+	}
+	return nil
+}
+
+// String returns policy as list of steps
+func (policy Policy) String() string {
+	var steps []string
+	for _, step := range policy.Steps {
+		steps = append(steps, step.String())
+	}
+	return strings.Join(steps, " ")
+}
+
+// Decide returns step for counter value: step is applied when counter reaches its level,
+// the last step is applied again for every greater value
+func (policy Policy) Decide(count int) (step Step, ok bool) {
+	for i := len(policy.Steps) - 1; i >= 0; i-- {
+		step = policy.Steps[i]
+		if step.Level == count || (step.Level < count && i == len(policy.Steps)-1) {
+			return step, true
+		}
+		if step.Level < count {
+			break
+		}
+	}
+	return Step{}, false
+}
+
+// MinLevel returns level of the first step, zero if policy has no steps
+func (policy Policy) MinLevel() int {
+	if len(policy.Steps) == 0 {
+		return 0
+	}
+	return policy.Steps[0].Level
+}
+
+// BanLevel returns level of the first ban step, zero if policy never bans
+func (policy Policy) BanLevel() int {
+	for _, step := range policy.Steps {
+		if step.Action == ActionBan {
+			return step.Level
+		}
+	}
+	return 0
+}
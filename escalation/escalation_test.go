@@ -0,0 +1,215 @@
+package escalation
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func mustParsePolicy(t *testing.T, steps ...string) Policy {
+	t.Helper()
+	policy, err := ParsePolicy(steps)
+	if err != nil {
+		t.Fatalf("ParsePolicy(%v): %s", steps, err)
+	}
+	return policy
+}
+
+func TestParsePolicyOrder(t *testing.T) {
+	policy := mustParsePolicy(t, "8:ban", "3:warn", "5:mute:1h")
+	if got, want := policy.String(), "3:warn 5:mute:1h 8:ban"; got != want {
+		t.Fatalf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestDecide(t *testing.T) {
+	policy := mustParsePolicy(t, "3:warn", "5:mute:1h", "8:ban")
+	tests := []struct {
+		count  int
+		ok     bool
+		action string
+	}{
+		{0, false, ""},
+		{1, false, ""},
+		{2, false, ""},
+		{3, true, ActionWarn},
+		{4, false, ""}, // between steps
+		{5, true, ActionMute},
+		{6, false, ""},
+		{7, false, ""},
+		{8, true, ActionBan},
+		{9, true, ActionBan}, // past the last step
+		{100, true, ActionBan},
+	}
+	for _, tt := range tests {
+		step, ok := policy.Decide(tt.count)
+		if ok != tt.ok || step.Action != tt.action {
+			t.Errorf("Decide(%d) = %v, %v, want %s, %v", tt.count, step, ok, tt.action, tt.ok)
+		}
+	}
+}
+
+func TestDecideLastStepDuration(t *testing.T) {
+	policy := mustParsePolicy(t, "2:mute:30m")
+	for _, count := range []int{2, 3, 10} {
+		step, ok := policy.Decide(count)
+		if !ok || step.Action != ActionMute || step.Duration != Duration(30*time.Minute) {
+			t.Errorf("Decide(%d) = %v, %v, want 2:mute:30m", count, step, ok)
+		}
+	}
+}
+
+func TestDecideEmpty(t *testing.T) {
+	var policy Policy
+	for _, count := range []int{0, 1, 10} {
+		if step, ok := policy.Decide(count); ok {
+			t.Errorf("Decide(%d) of empty policy = %v", count, step)
+		}
+	}
+}
+
+func TestLevels(t *testing.T) {
+	tests := []struct {
+		steps    []string
+		minLevel int
+		banLevel int
+	}{
+		{nil, 0, 0},
+		{[]string{"3:warn"}, 3, 0},
+		{[]string{"5:ban"}, 5, 5},
+		{[]string{"2:warn", "4:mute:1d", "6:ban", "9:ban:1w"}, 2, 6},
+		{[]string{"4:mute:1h", "1:warn"}, 1, 0},
+	}
+	for _, tt := range tests {
+		policy := mustParsePolicy(t, tt.steps...)
+		if got := policy.MinLevel(); got != tt.minLevel {
+			t.Errorf("%v: MinLevel() = %d, want %d", tt.steps, got, tt.minLevel)
+		}
+		if got := policy.BanLevel(); got != tt.banLevel {
+			t.Errorf("%v: BanLevel() = %d, want %d", tt.steps, got, tt.banLevel)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		str  string
+		want time.Duration
+		norm string
+	}{
+		{"1m", time.Minute, "1m"},
+		{"30m", 30 * time.Minute, "30m"},
+		{"90m", 90 * time.Minute, "1h30m"},
+		{"2h", 2 * time.Hour, "2h"},
+		{"1d12h", 36 * time.Hour, "1d12h"},
+		{"1D12H", 36 * time.Hour, "1d12h"},
+		{"7d", 7 * 24 * time.Hour, "1w"},
+		{"2w3d", 17 * 24 * time.Hour, "2w3d"},
+		{"1w1d1h1m", 8*24*time.Hour + time.Hour + time.Minute, "1w1d1h1m"},
+	}
+	for _, tt := range tests {
+		d, err := ParseDuration(tt.str)
+		if err != nil {
+			t.Errorf("ParseDuration(%q): %s", tt.str, err)
+			continue
+		}
+		if time.Duration(d) != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, want %v", tt.str, time.Duration(d), tt.want)
+		}
+		if got := d.String(); got != tt.norm {
+			t.Errorf("ParseDuration(%q).String() = %q, want %q", tt.str, got, tt.norm)
+		}
+		// formatted duration is parsed back to the same value
+		back, err := ParseDuration(d.String())
+		if err != nil || back != d {
+			t.Errorf("ParseDuration(%q) = %v, %v, want %v", d.String(), back, err, d)
+		}
+	}
+}
+
+func TestParseDurationInvalid(t *testing.T) {
+	for _, str := range []string{"", "0m", "1", "m", "1s", "1.5h", "-1h", "1h 30m", "h1", "abc"} {
+		if d, err := ParseDuration(str); err == nil {
+			t.Errorf("ParseDuration(%q) = %v, want error", str, d)
+		}
+		if str != "" && str != "0m" && IsDuration(str) {
+			t.Errorf("IsDuration(%q) = true", str)
+		}
+	}
+}
+
+func TestDurationJSON(t *testing.T) {
+	step := Step{Level: 5, Action: ActionMute, Duration: Duration(36 * time.Hour)}
+	data, err := json.Marshal(step)
+	if err != nil {
+		t.Fatalf("Marshal: %s", err)
+	}
+	if got, want := string(data), `{"level":5,"action":"mute","duration":"1d12h"}`; got != want {
+		t.Fatalf("Marshal = %s, want %s", got, want)
+	}
+	var back Step
+	if err = json.Unmarshal(data, &back); err != nil || back != step {
+		t.Fatalf("Unmarshal = %v, %v, want %v", back, err, step)
+	}
+
+	var ban Step
+	if err = json.Unmarshal([]byte(`{"level":8,"action":"ban","duration":"0m"}`), &ban); err != nil || ban.Duration != 0 {
+		t.Fatalf("Unmarshal of ban forever = %v, %v", ban, err)
+	}
+}
+
+func TestParseStepInvalid(t *testing.T) {
+	for _, str := range []string{
+		"",
+		"3",
+		"warn",
+		"0:warn",
+		"-1:ban",
+		"x:ban",
+		"3:kick",
+		"3:warn:1h",   // warning has no duration
+		"5:mute",      // mute requires duration
+		"5:mute:1s",   // invalid duration
+		"5:mute:1h:1", // too many parts
+	} {
+		if step, err := ParseStep(str); err == nil {
+			t.Errorf("ParseStep(%q) = %v, want error", str, step)
+		}
+	}
+}
+
+func TestValidateInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+	}{
+		{"duplicate levels", Policy{Steps: []Step{{Level: 3, Action: ActionWarn}, {Level: 3, Action: ActionBan}}}},
+		{"descending levels", Policy{Steps: []Step{{Level: 5, Action: ActionBan}, {Level: 3, Action: ActionWarn}}}},
+		{"zero level", Policy{Steps: []Step{{Level: 0, Action: ActionBan}}}},
+		{"unknown action", Policy{Steps: []Step{{Level: 1, Action: "kick"}}}},
+		{"mute without duration", Policy{Steps: []Step{{Level: 2, Action: ActionMute}}}},
+		{"warn with duration", Policy{Steps: []Step{{Level: 2, Action: ActionWarn, Duration: Duration(time.Hour)}}}},
+	}
+	for _, tt := range tests {
+		if err := tt.policy.Validate(); err == nil {
+			t.Errorf("%s: Validate(%s) accepted invalid policy", tt.name, tt.policy)
+		}
+	}
+
+	if _, err := ParsePolicy([]string{"3:warn", "3:ban"}); err == nil {
+		t.Error("ParsePolicy accepted duplicate levels")
+	}
+}
+
+func TestValidateValid(t *testing.T) {
+	policies := []Policy{
+		{},
+		{Steps: []Step{{Level: 1, Action: ActionBan}}},
+		{Steps: []Step{{Level: 1, Action: ActionWarn}, {Level: 2, Action: ActionMute, Duration: Duration(time.Hour)}, {Level: 3, Action: ActionBan, Duration: Duration(24 * time.Hour)}}},
+	}
+	for _, policy := range policies {
+		if err := policy.Validate(); err != nil {
+			t.Errorf("Validate(%s): %s", policy, err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Package flood limits rate of messages of users in chats with sliding window,
+// it doesn't depend on Telegram and database
+package flood
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock returns current time, it is replaced with fake clock to control time
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock is a clock with system time
+var SystemClock Clock = systemClock{}
+
+// Limit is a maximum of messages in window
+type Limit struct {
+	Messages int
+	Window   time.Duration
+}
+
+type key struct {
+	chatID int64
+	userID int
+}
+
+type hit struct {
+	time      time.Time
+	messageID int
+}
+
+type history struct {
+	hits   []hit
+	window time.Duration
+}
+
+// Limiter counts messages of users in chats, it is safe for concurrent use
+type Limiter struct {
+	clock     Clock
+	histories map[key]*history
+	mutex     sync.Mutex
+}
+
+// NewLimiter returns limiter with clock
+func NewLimiter(clock Clock) *Limiter {
+	return &Limiter{
+		clock:     clock,
+		histories: make(map[key]*history),
+	}
+}
+
+// Hit counts message of user in chat, if limit is exceeded it returns IDs of messages in window (burst)
+// and forgets them, so the next burst is counted from scratch
+func (l *Limiter) Hit(chatID int64, userID int, messageID int, limit Limit) (burst []int, exceeded bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.clock.Now()
+	k := key{chatID: chatID, userID: userID}
+	h := l.histories[k]
+	if h == nil {
+		h = new(history)
+		l.histories[k] = h
+	}
+	h.window = limit.Window
+	h.hits = append(h.prune(now), hit{time: now, messageID: messageID})
+
+	if limit.Messages <= 0 || len(h.hits) <= limit.Messages {
+		return nil, false
+	}
+	for _, hit := range h.hits {
+		burst = append(burst, hit.messageID)
+	}
+	delete(l.histories, k)
+	return burst, true
+}
+
+// Reset forgets messages of user in chat
+func (l *Limiter) Reset(chatID int64, userID int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.histories, key{chatID: chatID, userID: userID})
+}
+
+// Cleanup forgets messages out of window, it should be called periodically to free memory
+func (l *Limiter) Cleanup() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.clock.Now()
+	for k, h := range l.histories {
+		if h.hits = h.prune(now); len(h.hits) == 0 {
+			delete(l.histories, k)
+		}
+	}
+}
+
+// prune returns hits in window ending at now
+func (h *history) prune(now time.Time) []hit {
+	begin := now.Add(-h.window)
+	i := 0
+	for i < len(h.hits) && !h.hits[i].time.After(begin) {
+		i++
+	}
+	return h.hits[i:]
+}
@@ -0,0 +1,211 @@
+package flood
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock moved by test
+type fakeClock struct {
+	now   time.Time
+	mutex sync.Mutex
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2018, time.March, 8, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+var testLimit = Limit{Messages: 3, Window: 10 * time.Second}
+
+func TestHitAtLimit(t *testing.T) {
+	l := NewLimiter(newFakeClock())
+	for id := 1; id <= testLimit.Messages; id++ {
+		if burst, exceeded := l.Hit(1, 1, id, testLimit); exceeded {
+			t.Fatalf("message %d: limit exceeded with burst %v", id, burst)
+		}
+	}
+	burst, exceeded := l.Hit(1, 1, 4, testLimit)
+	if !exceeded {
+		t.Fatal("message over limit is not detected")
+	}
+	if want := []int{1, 2, 3, 4}; !reflect.DeepEqual(burst, want) {
+		t.Fatalf("burst = %v, want %v", burst, want)
+	}
+}
+
+func TestHitWindowBoundary(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(clock)
+
+	l.Hit(1, 1, 1, testLimit)
+	clock.Add(time.Second)
+	l.Hit(1, 1, 2, testLimit)
+	l.Hit(1, 1, 3, testLimit)
+
+	// the first message is exactly at the beginning of window, so it is out of window
+	clock.Add(testLimit.Window - time.Second)
+	if burst, exceeded := l.Hit(1, 1, 4, testLimit); exceeded {
+		t.Fatalf("message out of window is counted, burst %v", burst)
+	}
+
+	// messages 2 and 3 are still in window
+	clock.Add(time.Second - time.Nanosecond)
+	burst, exceeded := l.Hit(1, 1, 5, testLimit)
+	if !exceeded {
+		t.Fatal("message over limit is not detected")
+	}
+	if want := []int{2, 3, 4, 5}; !reflect.DeepEqual(burst, want) {
+		t.Fatalf("burst = %v, want %v", burst, want)
+	}
+}
+
+func TestHitSlowMessages(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(clock)
+	for id := 1; id <= 100; id++ {
+		if burst, exceeded := l.Hit(1, 1, id, testLimit); exceeded {
+			t.Fatalf("message %d: limit exceeded with burst %v", id, burst)
+		}
+		clock.Add(testLimit.Window / 2)
+	}
+}
+
+func TestHitResetAfterBurst(t *testing.T) {
+	l := NewLimiter(newFakeClock())
+	for id := 1; id <= testLimit.Messages+1; id++ {
+		l.Hit(1, 1, id, testLimit)
+	}
+
+	// the next burst is counted from scratch
+	for id := 10; id < 10+testLimit.Messages; id++ {
+		if burst, exceeded := l.Hit(1, 1, id, testLimit); exceeded {
+			t.Fatalf("message %d: limit exceeded with burst %v after reset", id, burst)
+		}
+	}
+	burst, exceeded := l.Hit(1, 1, 20, testLimit)
+	if !exceeded {
+		t.Fatal("the second burst is not detected")
+	}
+	if want := []int{10, 11, 12, 20}; !reflect.DeepEqual(burst, want) {
+		t.Fatalf("burst = %v, want %v", burst, want)
+	}
+}
+
+func TestHitIsolation(t *testing.T) {
+	l := NewLimiter(newFakeClock())
+	for id := 1; id <= testLimit.Messages; id++ {
+		l.Hit(1, 1, id, testLimit)
+	}
+
+	// the same user in other chat and other user in the same chat have own counters
+	for _, k := range []key{{chatID: 2, userID: 1}, {chatID: 1, userID: 2}} {
+		for id := 1; id <= testLimit.Messages; id++ {
+			if burst, exceeded := l.Hit(k.chatID, k.userID, id, testLimit); exceeded {
+				t.Fatalf("chat %d user %d: limit exceeded with burst %v", k.chatID, k.userID, burst)
+			}
+		}
+	}
+
+	burst, exceeded := l.Hit(1, 1, 4, testLimit)
+	if !exceeded || len(burst) != testLimit.Messages+1 {
+		t.Fatalf("Hit = %v, %v, want burst of %d messages", burst, exceeded, testLimit.Messages+1)
+	}
+	// burst of one user doesn't reset counters of others
+	if burst, exceeded := l.Hit(2, 1, 4, testLimit); !exceeded || len(burst) != testLimit.Messages+1 {
+		t.Fatalf("Hit = %v, %v, want burst of %d messages", burst, exceeded, testLimit.Messages+1)
+	}
+}
+
+func TestHitNoLimit(t *testing.T) {
+	l := NewLimiter(newFakeClock())
+	for id := 1; id <= 10; id++ {
+		if _, exceeded := l.Hit(1, 1, id, Limit{Window: time.Minute}); exceeded {
+			t.Fatal("limit without messages is exceeded")
+		}
+	}
+}
+
+func TestReset(t *testing.T) {
+	l := NewLimiter(newFakeClock())
+	for id := 1; id <= testLimit.Messages; id++ {
+		l.Hit(1, 1, id, testLimit)
+	}
+	l.Reset(1, 1)
+	if burst, exceeded := l.Hit(1, 1, 4, testLimit); exceeded {
+		t.Fatalf("limit exceeded after reset with burst %v", burst)
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(clock)
+	l.Hit(1, 1, 1, testLimit)
+	l.Hit(1, 2, 1, Limit{Messages: 3, Window: time.Minute})
+	clock.Add(testLimit.Window / 2)
+	l.Hit(1, 1, 2, testLimit)
+
+	l.Cleanup()
+	if len(l.histories) != 2 {
+		t.Fatalf("%d histories after cleanup, want 2", len(l.histories))
+	}
+
+	// the first message of user 1 is out of window, the second one is kept
+	clock.Add(testLimit.Window / 2)
+	l.Cleanup()
+	if h := l.histories[key{chatID: 1, userID: 1}]; h == nil || len(h.hits) != 1 || h.hits[0].messageID != 2 {
+		t.Fatalf("history of user 1 after cleanup = %+v, want message 2", h)
+	}
+
+	// user 1 is forgotten, user 2 has longer window
+	clock.Add(testLimit.Window / 2)
+	l.Cleanup()
+	if len(l.histories) != 1 || l.histories[key{chatID: 1, userID: 2}] == nil {
+		t.Fatalf("histories after cleanup = %v, want history of user 2", l.histories)
+	}
+
+	clock.Add(time.Minute)
+	l.Cleanup()
+	if len(l.histories) != 0 {
+		t.Fatalf("%d histories after cleanup, want 0", len(l.histories))
+	}
+}
+
+func TestHitConcurrent(t *testing.T) {
+	l := NewLimiter(newFakeClock())
+	limit := Limit{Messages: 1000, Window: time.Minute}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for id := 0; id < 100; id++ {
+				l.Hit(int64(w%2), w, id, limit)
+				if id%10 == 0 {
+					l.Cleanup()
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if len(l.histories) != 8 {
+		t.Fatalf("%d histories, want 8", len(l.histories))
+	}
+}
@@ -0,0 +1,78 @@
+package httpserver
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	adminParam    = "token"
+	adminHeader   = "X-Admin-Token"
+	adminCookie   = "admin"
+	adminMaxAge   = 30 * 24 * 60 * 60
+	redirectParam = "redirect"
+)
+
+const adminForm = `<form method="post" action="/admin">
+	<p>%s: <input type="password" name="%s" autocomplete="current-password"> <input type="submit" value="%s"></p>
+	<p>%s</p>
+	<input type="hidden" name="%s" value="%s">
+</form>`
+
+// isWebAdmin returns true if web viewer is archive admin, admin token is taken from header X-Admin-Token
+// or cookie admin set by login form, archive has no admins if token isn't set
+func (s *Server) isWebAdmin(c *gin.Context) bool {
+	if s.AdminToken == "" {
+		return false
+	}
+	if token := c.GetHeader(adminHeader); token != "" {
+		return s.checkAdminToken(token)
+	}
+	token, err := c.Cookie(adminCookie)
+	return err == nil && s.checkAdminToken(token)
+}
+
+func (s *Server) checkAdminToken(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.AdminToken)) == 1
+}
+
+// adminPage shows login form of archive admins, token is sent by POST so it doesn't get to URL
+func (s *Server) adminPage(c *gin.Context) {
+	lang := getWebLanguage(c)
+	body := fmt.Sprintf(adminForm, i18n.T(lang, "web.admin_token"), adminParam, i18n.T(lang, "web.admin_login"),
+		i18n.T(lang, "web.admin_hint"), redirectParam, html.EscapeString(getLocalRedirect(c.Query(redirectParam))))
+	c.Header("X-XSS-Protection", "1; mode=block")
+	c.Data(http.StatusOK, "text/html", parseTemplate(c, lang, body))
+}
+
+// adminLogin stores admin token to cookie and redirects back, wrong or empty token logs out
+func (s *Server) adminLogin(c *gin.Context) {
+	token := c.PostForm(adminParam)
+	secure := isHTTPS(c)
+	if s.AdminToken != "" && s.checkAdminToken(token) {
+		c.SetCookie(adminCookie, token, adminMaxAge, "/", "", secure, true)
+	} else {
+		c.SetCookie(adminCookie, "", -1, "/", "", secure, true)
+	}
+	c.Redirect(http.StatusSeeOther, getLocalRedirect(c.PostForm(redirectParam)))
+}
+
+// getLocalRedirect returns path of archive page to redirect to, links to other sites are replaced with main page
+func getLocalRedirect(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "/\\") {
+		return "/"
+	}
+	return path
+}
+
+// isHTTPS returns true if request came by HTTPS directly or through proxy
+func isHTTPS(c *gin.Context) bool {
+	return c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+}
@@ -0,0 +1,55 @@
+package httpserver
+
+import (
+	"log"
+	"strings"
+
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// Archive command shows or changes web archive settings of chat
+func (s *Server) Archive(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+
+	settings, err := db.GetChatSettings(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in Archive -> GetChatSettings: %s", err)
+		return
+	}
+
+	lang := getMessageLanguage(msg)
+	if len(args) > 0 {
+		if !s.checkRole(msg, RoleAdmin) {
+			return
+		}
+
+		switch {
+		case args[0] == "hide":
+			settings.Hidden = true
+		case args[0] == "show":
+			settings.Hidden = false
+		case args[0] == "feed" && len(args) == 2 && (args[1] == db.FeedModeMessage || args[1] == db.FeedModeDay):
+			settings.FeedMode = args[1]
+		default:
+			s.SendError(i18n.T(lang, "archive.usage"), msg)
+			return
+		}
+		if err = db.SaveChatSettings(settings); err != nil {
+			log.Printf("Error in Archive -> SaveChatSettings: %s", err)
+			return
+		}
+	}
+
+	visibility := i18n.T(lang, "archive.shown")
+	if settings.Hidden {
+		visibility = i18n.T(lang, "archive.hidden")
+	}
+	feedMode := settings.FeedMode
+	if feedMode == "" {
+		feedMode = db.FeedModeMessage
+	}
+	s.SendMessage(i18n.T(lang, "archive.description", visibility, feedMode), msg.Chat.ID, msg.MessageID)
+}
@@ -10,18 +10,15 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/escalation"
+	"github.com/elemc/gotelegrambot/i18n"
 
 	"gopkg.in/telegram-bot-api.v4"
 )
 
-// PhotosCache type for store users photo filenames by id
-type PhotosCache map[int64]string
-
-// FilesCache type for store files
-type FilesCache map[string]string
-
 // UpdatePhotoCache function update photos cache of users
 func (s *Server) UpdatePhotoCache() {
 	users, err := db.GetUsers()
@@ -30,16 +27,19 @@ func (s *Server) UpdatePhotoCache() {
 		return
 	}
 
-	s.PhotoCache = make(PhotosCache) // new cache
+	names := make(map[int64]string) // new cache
 
 	for _, user := range users {
-		s.GetPhoto(int64(user.ID))
+		if filename, ok := s.downloadPhoto(int64(user.ID)); ok {
+			names[int64(user.ID)] = filename
+		}
 	}
+	s.PhotoCache.Replace(names)
 }
 
 // GetPhotoFileName returns name photo file
 func (s *Server) GetPhotoFileName(userID int64) (result string) {
-	if fn, ok := s.PhotoCache[userID]; ok {
+	if fn, ok := s.PhotoCache.Get(userID); ok {
 		result = getFileName("static", fn)
 	} else {
 		result = getFileName("static", "nobody.png")
@@ -81,8 +81,14 @@ func (s *Server) GetFileNameByFileIDURL(chatID int64, fileID string) (filename s
 	return
 }
 
-// GetPhoto function download user photo and return file name for html tag img
+// GetPhoto function download user photo and store file name for html tag img to cache
 func (s *Server) GetPhoto(chatID int64) {
+	if filename, ok := s.downloadPhoto(chatID); ok {
+		s.PhotoCache.Set(chatID, filename)
+	}
+}
+
+func (s *Server) downloadPhoto(chatID int64) (filename string, ok bool) {
 	config := tgbotapi.NewUserProfilePhotos(int(chatID))
 	photos, err := s.Bot.GetUserProfilePhotos(config)
 	if err != nil {
@@ -99,16 +105,16 @@ func (s *Server) GetPhoto(chatID int64) {
 
 	link, err := s.Bot.GetFileDirectURL(res.FileID)
 	if err != nil {
-		log.Printf(err.Error())
+		log.Printf("Error in GetFileDirectURL for ID %d: %s", chatID, err)
 		return
 	}
-	filename := fmt.Sprintf("%d.jpg", chatID)
+	filename = fmt.Sprintf("%d.jpg", chatID)
 	err = downloadImage(link, getFileName(s.StaticDirPath, filename))
 	if err != nil {
 		log.Printf("Error in downloadImage: %s", err)
 		return
 	}
-	s.PhotoCache[chatID] = filename
+	ok = true
 
 	return
 }
@@ -123,7 +129,7 @@ func (s *Server) GetFile(fileID string, chatID int64) {
 		return
 	}
 
-	log.Printf(f.FilePath)
+	log.Print(f.FilePath)
 
 	// check directory
 	dir := filepath.Dir(f.FilePath)
@@ -140,7 +146,7 @@ func (s *Server) GetFile(fileID string, chatID int64) {
 		log.Printf("Error in MkdirAll for FileID [%s]: %s", fileID, err)
 		return
 	}
-	//s.FileCache[f.FileID] = filepath.Join("static", f.FilePath)
+	//s.FileCache.Set(f.FileID, filepath.Join("static", f.FilePath))
 	err = db.SaveFile(&f, chatID)
 	if err != nil {
 		log.Printf("Error in SaveFile for FileID [%s]: %s", fileID, err)
@@ -180,41 +186,6 @@ func (s *Server) SendMessage(msgText string, chatID int64, replyID int) {
 	}
 }
 
-// CommandHandler function for handle commands for bot
-func (s *Server) CommandHandler(msg *tgbotapi.Message) {
-	if msg == nil {
-		return
-	}
-	switch msg.Command() {
-	case "start":
-		s.SendMessage("Привет,", msg.Chat.ID, msg.MessageID)
-	case "help":
-		s.SendHelp(msg)
-	case "ping":
-		s.SendPing(msg)
-	case "ban":
-		s.BanUnbanUser(msg, true)
-	case "unban":
-		s.BanUnbanUser(msg, false)
-	case "banlist":
-		s.BanList(msg)
-	case "clearcens":
-		s.ClearCens(msg)
-	case "mycens":
-		s.GetCensLevel(msg)
-	case "warn":
-		s.WarnAdd(msg)
-	case "clearwarn":
-		s.WarnClear(msg)
-	case "mywarn":
-		s.GetWarnLevel(msg)
-	default:
-		log.Printf("Unknown command: %s", msg.Command())
-		// 	if msg.
-		// 	s.SendMessage("Неизвестная команда", msg.Chat.ID, msg.MessageID)
-	}
-}
-
 // SendError simple shell for SendMessage with error
 func (s *Server) SendError(msgText string, msg *tgbotapi.Message) {
 	s.SendMessage(msgText, msg.Chat.ID, msg.MessageID)
@@ -222,46 +193,18 @@ func (s *Server) SendError(msgText string, msg *tgbotapi.Message) {
 
 // UserIsAdmin returns user is admin or not
 func (s *Server) UserIsAdmin(userID int, chat *tgbotapi.Chat) (ok bool, err error) {
-	if chat == nil {
-		return false, fmt.Errorf("Chat pointer is nil")
-	}
-	cc := tgbotapi.ChatConfig{}
-	if chat.IsSuperGroup() || chat.IsGroup() {
-		if chat.ChatConfig().SuperGroupUsername == "" {
-			return false, fmt.Errorf("Chat with all admins!")
-		}
-		cc.SuperGroupUsername = chat.ChatConfig().SuperGroupUsername
-	} else {
-		cc.ChatID = chat.ChatConfig().ChatID
-	}
-	var admins []tgbotapi.ChatMember
-	if admins, err = s.Bot.GetChatAdministrators(cc); err != nil {
-		log.Printf("Error in GetChatAdministrators: %s", err)
+	role, err := s.getUserRole(userID, chat)
+	if err != nil {
+		log.Printf("Error in getUserRole: %s", err)
 		return
 	}
-
-	ok = false
-	for _, admin := range admins {
-		if admin.User.ID == userID {
-			ok = true
-			break
-		}
-	}
+	ok = role >= RoleAdmin
 	return
 }
 
 // UserIsBanned returns ban status user true or false
 func (s *Server) UserIsBanned(userID int, chat *tgbotapi.Chat) (banned bool, err error) {
-	cc := tgbotapi.ChatConfigWithUser{}
-	if chat.IsSuperGroup() || chat.IsGroup() {
-		if chat.ChatConfig().SuperGroupUsername == "" {
-			return false, fmt.Errorf("Chat with all admins!")
-		}
-		cc.SuperGroupUsername = chat.ChatConfig().SuperGroupUsername
-	} else {
-		cc.ChatID = chat.ID
-	}
-	cc.UserID = userID
+	cc := tgbotapi.ChatConfigWithUser{ChatID: chat.ID, UserID: userID}
 
 	member, err := s.Bot.GetChatMember(cc)
 	if err != nil {
@@ -292,62 +235,34 @@ func (s *Server) BanList(msg *tgbotapi.Message) {
 		}
 	}
 
+	lang := getMessageLanguage(msg)
 	if len(bannedList) == 0 {
-		s.SendMessage("Ура! Мы чисты! Забаненых нет", msg.Chat.ID, msg.MessageID)
+		s.SendMessage(i18n.T(lang, "banlist.empty"), msg.Chat.ID, msg.MessageID)
 		return
 	}
-	msgText := fmt.Sprintf("Список забанненных лиц:\n%s", strings.Join(bannedList, "\n"))
+	msgText := i18n.T(lang, "banlist.title", strings.Join(bannedList, "\n"))
 	s.SendMessage(msgText, msg.Chat.ID, msg.MessageID)
 }
 
 // BanUnbanUser method ban selected user
 func (s *Server) BanUnbanUser(msg *tgbotapi.Message, ban bool) {
-	isAdmin, err := s.UserIsAdmin(msg.From.ID, msg.Chat)
-	if err != nil {
-		return
-	}
-	if !isAdmin {
-		s.SendError("Не удалось установить Вашу причастность к администраторам группы!", msg)
+	user, _, ok := s.getTarget(msg)
+	if !ok {
 		return
 	}
 
-	user, err := db.GetUser(msg.CommandArguments())
-	if err != nil {
-		errStrings := strings.Split(err.Error(), "\n")
-		if len(errStrings) > 1 {
-			switch errStrings[0] {
-			case "User not found":
-				s.SendError(fmt.Sprintf("Пользователь %s не найден", msg.CommandArguments()), msg)
-				return
-			case "Many users":
-				s.SendError(fmt.Sprintf("Найдено более одного пользователя, уточните:\n%s", strings.Join(errStrings[1:], "\n")), msg)
-				return
-			default:
-				s.SendError(fmt.Sprintf("Произошла неизвестная ошибка при поиске пользователя: %s", err.Error()), msg)
-				return
-			}
-		}
-	}
-
-	if user != nil {
-		userIsAdmin, _ := s.UserIsAdmin(user.ID, msg.Chat)
-		if userIsAdmin {
-			s.SendError(fmt.Sprintf("Пользователь [%s] является администратором группы. Администраторов банить нельзя! Они хорошие!", user.String()), msg)
-			return
-		}
-	} else {
-		s.SendError(fmt.Sprintf("Пользователь [%s] не найден", msg.CommandArguments()), msg)
+	if ban && !s.checkTargetRole(msg, user) {
 		return
 	}
 
 	ok, err := s.kickUser(user.ID, msg.Chat, ban)
-
 	if err != nil {
 		log.Printf("Error in KickChatMember: %s", err)
+		s.SendError(i18n.ErrorText(getMessageLanguage(msg), err), msg)
 		return
 	}
 	if ok {
-		s.SendMessage("Успешно выполнено.", msg.Chat.ID, msg.MessageID)
+		s.SendMessage(i18n.T(getMessageLanguage(msg), "done"), msg.Chat.ID, msg.MessageID)
 	}
 }
 
@@ -361,152 +276,178 @@ func (s *Server) SendPing(msg *tgbotapi.Message) {
 		s.SendMessage("Request timed out", msg.Chat.ID, msg.MessageID)
 		return
 	}
-	pingMsg := fmt.Sprintf("%s пинг от тебя %3.3f", msg.From.String(), r.Float32())
+	pingMsg := i18n.T(getMessageLanguage(msg), "ping.reply", msg.From.String(), r.Float32())
 	s.SendMessage(pingMsg, msg.Chat.ID, msg.MessageID)
 }
 
-// SendHelp sends help message to chat
-func (s *Server) SendHelp(msg *tgbotapi.Message) {
-	helpMsg :=
-		`Помощь по командам бота.
-/start - приветствие (стандартная для любого бота Telegram)
-/ban @username - забанить пользователя в группе (бот должен иметь административные права в группе)
-/unban @username - разбанить пользователя в группе (бот должен иметь административные права в группе)
-/banlist - показать список забаненых пользователей
-/clearcens - очистить счетчик бранных слов
-/mycens - показать собственный счетчик бранных слов
-/ping - шуточный пинг`
-	s.SendMessage(helpMsg, msg.Chat.ID, msg.MessageID)
+// FillCens load censore database: default dictionary from mat.txt and named dictionaries from cens/*.txt
+func (s *Server) FillCens() {
+	words, err := readCensDictionary(filepath.Join(s.StaticDirPath, "mat.txt"))
+	if err != nil {
+		log.Printf("Error in reading mat.txt: %s", err)
+		return
+	}
+	dictionaries := map[string][]string{defaultCensDictionary: words}
+
+	files, err := filepath.Glob(filepath.Join(s.StaticDirPath, "cens", "*.txt"))
+	if err != nil {
+		log.Printf("Error in list cens dictionaries: %s", err)
+	}
+	for _, fn := range files {
+		name := strings.TrimSuffix(filepath.Base(fn), ".txt")
+		if words, err = readCensDictionary(fn); err != nil {
+			log.Printf("Error in reading cens dictionary %s: %s", fn, err)
+			continue
+		}
+		dictionaries[name] = words
+	}
+
+	if err = s.CensList.Replace(dictionaries); err != nil {
+		log.Printf("Error in compile cens dictionaries: %s", err)
+		return
+	}
+	s.CensFilters.Reset()
+	log.Printf("Cens database filled.")
 }
 
-// FillCens load censore database
-func (s *Server) FillCens() {
-	f, err := os.Open(filepath.Join(s.StaticDirPath, "mat.txt"))
+// readCensDictionary reads words separated by commas or new lines from file
+func readCensDictionary(fn string) (words []string, err error) {
+	f, err := os.Open(fn)
 	if err != nil {
-		log.Printf("Error in open mat.txt: %s", err)
 		return
 	}
 	defer f.Close()
 
 	data, err := ioutil.ReadAll(f)
 	if err != nil {
-		log.Printf("Error in reading mat.txt: %s", err)
 		return
 	}
 
-	s.CensList = []string{}
-
-	words := strings.Split(string(data), ",")
-	for _, word := range words {
+	for _, word := range strings.FieldsFunc(string(data), func(r rune) bool { return r == ',' || r == '\n' }) {
 		sWord := strings.TrimSpace(word)
 		if sWord == "" {
 			continue
 		}
-		s.CensList = append(s.CensList, sWord)
+		words = append(words, sWord)
 	}
-	log.Printf("Cens database filled.")
+	return
 }
 
-// Cens method for censore messages
+// Cens method for censore messages, it checks messages in chats with enabled cens only
 func (s *Server) Cens(msg *tgbotapi.Message) {
-	lineList := strings.Split(msg.Text, "\n")
-	var wordList []string
-	for _, line := range lineList {
-		wl := strings.Split(line, " ")
-		wordList = append(wordList, wl...)
-	}
-	for _, word := range wordList {
-		uWord := strings.ToUpper(word)
-		for _, mWord := range s.CensList {
-			umWord := strings.ToUpper(mWord)
-			if umWord == uWord {
-				s.censWord(msg, mWord)
-			}
-		}
-	}
-}
-
-// ClearCens command for clean censore level
-func (s *Server) ClearCens(msg *tgbotapi.Message) {
-	isAdmin, err := s.UserIsAdmin(msg.From.ID, msg.Chat)
+	settings, err := db.GetChatSettings(msg.Chat.ID)
 	if err != nil {
+		log.Printf("Error in Cens -> GetChatSettings: %s", err)
 		return
 	}
-	if !isAdmin {
-		s.SendError("Не удалось установить Вашу причастность к администраторам группы!", msg)
+	if !settings.Cens.Enabled {
 		return
 	}
 
-	user, err := db.GetUser(msg.CommandArguments())
+	filter, err := s.getCensFilter(msg.Chat.ID, settings.Cens)
 	if err != nil {
-		errStrings := strings.Split(err.Error(), "\n")
-		if len(errStrings) > 1 {
-			switch errStrings[0] {
-			case "User not found":
-				s.SendError(fmt.Sprintf("Пользователь %s не найден", msg.CommandArguments()), msg)
-				return
-			case "Many users":
-				s.SendError(fmt.Sprintf("Найдено более одного пользователя, уточните:\n%s", strings.Join(errStrings[1:], "\n")), msg)
-				return
-			default:
-				s.SendError(fmt.Sprintf("Произошла неизвестная ошибка при поиске пользователя: %s", err.Error()), msg)
-				return
-			}
-		}
+		log.Printf("Error in Cens -> getCensFilter: %s", err)
+		return
+	}
+	text := msg.Text
+	if msg.Caption != "" {
+		text += "\n" + msg.Caption
+	}
+	match, ok := filter.Match(text)
+	if !ok {
+		return
 	}
+	// role is requested from Telegram only for matched messages
+	if s.isCensExempt(msg, settings.Cens) {
+		return
+	}
+	s.censWord(msg, match.Entry, settings)
+}
 
-	if user == nil {
-		s.SendError(fmt.Sprintf("Пользователь %s не найден.", msg.CommandArguments()), msg)
+// ClearCens command for clean censore level
+func (s *Server) ClearCens(msg *tgbotapi.Message) {
+	user, _, ok := s.getTarget(msg)
+	if !ok {
 		return
 	}
 
-	err = db.ClearCensLevel(user)
+	err := db.ClearCensLevel(msg.Chat.ID, user)
 	if err != nil {
 		log.Printf("Error in ClearCens -> ClearCensLevel: %s", err)
 		return
 	}
-	s.SendError("Выполнено успешно.", msg)
+	s.SendError(i18n.T(getMessageLanguage(msg), "done"), msg)
 }
 
 // GetCensLevel send message with current censore level for user
 func (s *Server) GetCensLevel(msg *tgbotapi.Message) {
-	currentLevel, err := db.GetCensLevel(msg.From)
+	lang := getMessageLanguage(msg)
+	currentLevel, err := db.GetCensLevel(msg.Chat.ID, msg.From)
 	if err != nil {
 		if err.Error() == "Key not found." {
-			s.SendError("Ты чист душой!", msg)
+			s.SendError(i18n.T(lang, "mycens.clean"), msg)
 			return
 		}
 		log.Printf("Error in GetCensLevel -> GetCensLevel: %s", err)
 		return
 	}
-	s.SendError(fmt.Sprintf("Твой личный счетчик бранных слов: %d", currentLevel), msg)
+	s.SendError(i18n.T(lang, "mycens.level", currentLevel), msg)
 }
 
-func (s *Server) censWord(msg *tgbotapi.Message, mWord string) {
+func (s *Server) censWord(msg *tgbotapi.Message, mWord string, settings *db.ChatSettings) {
 	log.Printf("[%s] cens word [%s] in text [%s]", msg.From.String(), mWord, msg.Text)
-	s.SendError(fmt.Sprintf("Перестаньте сказать, %s! Вы не на привозе!", msg.From.String()), msg)
-	cur, err := db.AddCensLevel(msg.From)
+	lang := getLanguage(msg.Chat, nil)
+	reason := i18n.T(lang, "cens.reason")
+	replyID := msg.MessageID
+	switch settings.Cens.GetAction() {
+	case db.CensActionReply:
+		s.SendError(i18n.T(lang, "cens.scold", msg.From.String()), msg)
+	case db.CensActionDelete:
+		if err := s.removeMessage(msg, fmt.Sprintf("%s (%s)", reason, mWord), 0); err != nil {
+			log.Printf("Error in censWord -> removeMessage: %s", err)
+		} else {
+			replyID = 0
+			s.SendMessage(i18n.T(lang, "cens.removed", msg.From.String()), msg.Chat.ID, 0)
+		}
+	}
+
+	cur, err := db.AddCensLevel(msg.Chat.ID, msg.From)
 	if err != nil {
 		log.Printf("Error in AddCensLevel: %s", err)
 		return
 	}
-	if cur > 5 {
-		userIsAdmin, _ := s.UserIsAdmin(msg.From.ID, msg.Chat)
-		if userIsAdmin {
-			return
-		}
+	policy := settings.GetCensPolicy()
+	if cur < policy.MinLevel() {
+		return
+	}
 
-		ok, err := s.kickUser(msg.From.ID, msg.Chat, true)
+	step, until, ok, err := s.escalate(msg.Chat, msg.From, cur, policy, reason)
+	if err != nil {
+		log.Printf("Error in censWord -> escalate: %s", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	if step.Action == escalation.ActionBan && step.Duration == 0 {
+		s.SendMessage(i18n.T(lang, "cens.banned", msg.From.String()), msg.Chat.ID, replyID)
+		return
+	}
+	s.SendMessage(formatStep(lang, step, msg.From, until, reason, db.GetChatLocation(msg.Chat.ID)), msg.Chat.ID, replyID)
+}
 
-		if err != nil {
-			log.Printf("Error in KickChatMember: %s", err)
-			return
-		}
-		if ok {
-			s.SendError(fmt.Sprintf("Поздравляю, %s! Вы превысили количество бранных слов в году и выбываете из чата!", msg.From.String()), msg)
-		}
+// removeMessage deletes message from chat, archive keeps the message marked as removed by moderation with reason,
+// zero issuerID means the bot
+func (s *Server) removeMessage(msg *tgbotapi.Message, reason string, issuerID int) error {
+	if err := s.deleteMessage(msg.Chat.ID, msg.MessageID); err != nil {
+		return err
 	}
-	return
+	return db.MarkMessageRemoved(msg, reason, issuerID)
+}
+
+// deleteMessage deletes message from chat
+func (s *Server) deleteMessage(chatID int64, messageID int) error {
+	return checkResponse(s.Bot.DeleteMessage(tgbotapi.NewDeleteMessage(chatID, messageID)))
 }
 
 func getFileName(staticDir, fn string) string {
@@ -533,136 +474,15 @@ func downloadImage(url string, filename string) (err error) {
 }
 
 func (s *Server) kickUser(userID int, chat *tgbotapi.Chat, ban bool) (ok bool, err error) {
-	ok = false
-	config := tgbotapi.ChatMemberConfig{}
-	config.UserID = userID
-	if chat.IsSuperGroup() || chat.IsGroup() {
-		if chat.ChatConfig().SuperGroupUsername == "" {
-			return false, fmt.Errorf("Chat with all admins!")
-		}
-		config.SuperGroupUsername = chat.ChatConfig().SuperGroupUsername
-		config.SuperGroupUsername = chat.ChatConfig().SuperGroupUsername
-		log.Printf("Kick from %s", config.SuperGroupUsername)
-	} else {
-		config.ChatID = chat.ChatConfig().ChatID
-	}
-
-	var resp tgbotapi.APIResponse
 	if ban {
-		resp, err = s.Bot.KickChatMember(config)
+		err = s.banUser(chat.ID, userID, time.Time{})
 	} else {
-		resp, err = s.Bot.UnbanChatMember(config)
+		err = s.unbanUser(chat.ID, userID)
 	}
 	if err != nil {
+		err = i18n.Errorf("ban.failed", err)
 		return
 	}
-	if !resp.Ok {
-		err = fmt.Errorf("Не удалось забанить/разбанить пользователя: code=%d description: %s", resp.ErrorCode, resp.Description)
-	} else {
-		ok = true
-	}
+	ok = true
 	return
 }
-
-func (s *Server) WarnAdd(msg *tgbotapi.Message) {
-	var (
-		user *tgbotapi.User
-		err  error
-	)
-	if user, err = db.GetUser(msg.CommandArguments()); err != nil {
-		errStrings := strings.Split(err.Error(), "\n")
-		if len(errStrings) > 1 {
-			switch errStrings[0] {
-			case "User not found":
-				s.SendError(fmt.Sprintf("Пользователь %s не найден", msg.CommandArguments()), msg)
-				return
-			case "Many users":
-				s.SendError(fmt.Sprintf("Найдено более одного пользователя, уточните:\n%s", strings.Join(errStrings[1:], "\n")), msg)
-				return
-			default:
-				s.SendError(fmt.Sprintf("Произошла неизвестная ошибка при поиске пользователя: %s", err.Error()), msg)
-				return
-			}
-		}
-	}
-	if user == nil {
-		s.SendError(fmt.Sprintf("Пользователь [%s] не найден", msg.CommandArguments()), msg)
-		return
-	}
-	if user.ID == msg.From.ID {
-		s.SendError("Сам себя? O_o", msg)
-		return
-	}
-
-	currentLevel, err := db.AddWarnLevel(user)
-	if err != nil {
-		log.Printf("Error in AddWarnLevel: %s", err)
-		return
-	}
-
-	if currentLevel >= 5 {
-		ok, err := s.kickUser(user.ID, msg.Chat, true)
-		if err != nil {
-			log.Printf("Error in kickUser: %s", err)
-			return
-		}
-		if ok {
-			s.SendMessage("Пользователь %s забанен!", msg.Chat.ID, -1)
-		}
-	}
-}
-
-func (s *Server) WarnClear(msg *tgbotapi.Message) {
-	isAdmin, err := s.UserIsAdmin(msg.From.ID, msg.Chat)
-	if err != nil {
-		return
-	}
-	if !isAdmin {
-		s.SendError("Не удалось установить Вашу причастность к администраторам группы!", msg)
-		return
-	}
-
-	user, err := db.GetUser(msg.CommandArguments())
-	if err != nil {
-		errStrings := strings.Split(err.Error(), "\n")
-		if len(errStrings) > 1 {
-			switch errStrings[0] {
-			case "User not found":
-				s.SendError(fmt.Sprintf("Пользователь %s не найден", msg.CommandArguments()), msg)
-				return
-			case "Many users":
-				s.SendError(fmt.Sprintf("Найдено более одного пользователя, уточните:\n%s", strings.Join(errStrings[1:], "\n")), msg)
-				return
-			default:
-				s.SendError(fmt.Sprintf("Произошла неизвестная ошибка при поиске пользователя: %s", err.Error()), msg)
-				return
-			}
-		}
-	}
-
-	if user == nil {
-		s.SendError(fmt.Sprintf("Пользователь %s не найден.", msg.CommandArguments()), msg)
-		return
-	}
-
-	err = db.ClearWarnLevel(user)
-	if err != nil {
-		log.Printf("Error in WarnClear -> ClearWarnLevel: %s", err)
-		return
-	}
-	s.SendError("Выполнено успешно.", msg)
-}
-
-// GetWarnLevel send message with current warning level for user
-func (s *Server) GetWarnLevel(msg *tgbotapi.Message) {
-	currentLevel, err := db.GetWarnLevel(msg.From)
-	if err != nil {
-		if err.Error() == "Key not found." {
-			s.SendError("Чист душой!", msg)
-			return
-		}
-		log.Printf("Error in GetWarnLevel -> GetWarnLevel: %s", err)
-		return
-	}
-	s.SendError(fmt.Sprintf("Уровень настороженности: %d", currentLevel), msg)
-}
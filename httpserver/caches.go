@@ -0,0 +1,158 @@
+package httpserver
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/elemc/gotelegrambot/censor"
+)
+
+// PhotosCache type for store users photo filenames by id, it is safe for concurrent use
+type PhotosCache struct {
+	names map[int64]string
+	mutex sync.RWMutex
+}
+
+// FilesCache type for store files, it is safe for concurrent use
+type FilesCache struct {
+	names map[string]string
+	mutex sync.RWMutex
+}
+
+// CensList type for store censore dictionaries by name, it is safe for concurrent use
+type CensList struct {
+	dictionaries map[string][]string
+	mutex        sync.RWMutex
+}
+
+// CensFilters type for store censore filters compiled for chats, it is safe for concurrent use
+type CensFilters struct {
+	filters map[int64]*censor.Filter
+	mutex   sync.RWMutex
+}
+
+// Get returns photo filename for user
+func (c *PhotosCache) Get(userID int64) (filename string, ok bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	filename, ok = c.names[userID]
+	return
+}
+
+// Set stores photo filename for user
+func (c *PhotosCache) Set(userID int64, filename string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.names == nil {
+		c.names = make(map[int64]string)
+	}
+	c.names[userID] = filename
+}
+
+// Replace stores all names in cache, names of other users set meanwhile by Set are kept
+func (c *PhotosCache) Replace(names map[int64]string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.names == nil {
+		c.names = make(map[int64]string, len(names))
+	}
+	for userID, filename := range names {
+		c.names[userID] = filename
+	}
+}
+
+// Get returns filename for file ID
+func (c *FilesCache) Get(fileID string) (filename string, ok bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	filename, ok = c.names[fileID]
+	return
+}
+
+// Set stores filename for file ID
+func (c *FilesCache) Set(fileID string, filename string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.names == nil {
+		c.names = make(map[string]string)
+	}
+	c.names[fileID] = filename
+}
+
+// Words returns words of dictionary, the list must not be modified
+func (c *CensList) Words(name string) (words []string, ok bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	words, ok = c.dictionaries[name]
+	return
+}
+
+// Names returns sorted names of dictionaries
+func (c *CensList) Names() (names []string) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	for name := range c.dictionaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// Replace replaces all dictionaries, list isn't changed if any dictionary can't be compiled to filter
+func (c *CensList) Replace(dictionaries map[string][]string) (err error) {
+	for name, words := range dictionaries {
+		if _, err = censor.New(words); err != nil {
+			return fmt.Errorf("dictionary %s: %s", name, err)
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.dictionaries = dictionaries
+	return
+}
+
+// Get returns filter compiled for chat
+func (c *CensFilters) Get(chatID int64) (filter *censor.Filter, ok bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	filter, ok = c.filters[chatID]
+	return
+}
+
+// Set stores filter compiled for chat
+func (c *CensFilters) Set(chatID int64, filter *censor.Filter) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.filters == nil {
+		c.filters = make(map[int64]*censor.Filter)
+	}
+	c.filters[chatID] = filter
+}
+
+// Remove removes filter of chat, it will be compiled again on next use
+func (c *CensFilters) Remove(chatID int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.filters, chatID)
+}
+
+// Reset removes filters of all chats
+func (c *CensFilters) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.filters = nil
+}
@@ -0,0 +1,138 @@
+package httpserver
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+const (
+	cacheWorkers = 8
+	cacheRounds  = 200
+)
+
+func TestPhotosCacheConcurrent(t *testing.T) {
+	var c PhotosCache
+	var wg sync.WaitGroup
+	for w := 0; w < cacheWorkers; w++ {
+		wg.Add(3)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < cacheRounds; i++ {
+				c.Set(int64(w*cacheRounds+i), fmt.Sprintf("set-%d", i))
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < cacheRounds; i++ {
+				c.Get(int64(w*cacheRounds + i))
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < cacheRounds/10; i++ {
+				c.Replace(map[int64]string{-int64(w + 1): "replaced"})
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// names set concurrently with Replace must survive it
+	for w := 0; w < cacheWorkers; w++ {
+		for i := 0; i < cacheRounds; i++ {
+			if _, ok := c.Get(int64(w*cacheRounds + i)); !ok {
+				t.Fatalf("name of user %d is lost", w*cacheRounds+i)
+			}
+		}
+		if name, ok := c.Get(-int64(w + 1)); !ok || name != "replaced" {
+			t.Fatalf("Get(%d) = %q, %v, want replaced", -(w + 1), name, ok)
+		}
+	}
+}
+
+func TestPhotosCacheReplaceOverwrites(t *testing.T) {
+	var c PhotosCache
+	c.Set(1, "old")
+	c.Replace(map[int64]string{1: "new", 2: "other"})
+	if name, _ := c.Get(1); name != "new" {
+		t.Fatalf("Get(1) = %q, want new", name)
+	}
+	if name, _ := c.Get(2); name != "other" {
+		t.Fatalf("Get(2) = %q, want other", name)
+	}
+}
+
+func TestFilesCacheConcurrent(t *testing.T) {
+	var c FilesCache
+	var wg sync.WaitGroup
+	for w := 0; w < cacheWorkers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < cacheRounds; i++ {
+				c.Set(fmt.Sprintf("%d-%d", w, i), "file")
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < cacheRounds; i++ {
+				c.Get(fmt.Sprintf("%d-%d", w, i))
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for w := 0; w < cacheWorkers; w++ {
+		for i := 0; i < cacheRounds; i++ {
+			if _, ok := c.Get(fmt.Sprintf("%d-%d", w, i)); !ok {
+				t.Fatalf("file %d-%d is lost", w, i)
+			}
+		}
+	}
+}
+
+func TestCensListConcurrent(t *testing.T) {
+	var c CensList
+	var wg sync.WaitGroup
+	for w := 0; w < cacheWorkers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < cacheRounds/10; i++ {
+				name := fmt.Sprintf("dict%d", w)
+				if err := c.Replace(map[string][]string{name: {"word"}}); err != nil {
+					t.Errorf("Replace: %s", err)
+					return
+				}
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < cacheRounds; i++ {
+				c.Names()
+				if words, ok := c.Words(fmt.Sprintf("dict%d", w)); ok && len(words) != 1 {
+					t.Errorf("Words = %v, want one word", words)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if names := c.Names(); len(names) != 1 {
+		t.Fatalf("Names() = %v, want one dictionary", names)
+	}
+}
+
+func TestCensListReplaceInvalid(t *testing.T) {
+	var c CensList
+	if err := c.Replace(map[string][]string{"good": {"word"}}); err != nil {
+		t.Fatalf("Replace: %s", err)
+	}
+	if err := c.Replace(map[string][]string{"bad": {"/[/"}}); err == nil {
+		t.Fatal("Replace accepted invalid dictionary")
+	}
+	if names := c.Names(); len(names) != 1 || names[0] != "good" {
+		t.Fatalf("Names() = %v, want [good]", names)
+	}
+}
@@ -0,0 +1,142 @@
+package httpserver
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+const calendarLevels = 4
+
+func (s *Server) calendarPage(c *gin.Context) {
+	strChatID := c.Param("chat_id")
+	chatID, err := strconv.ParseInt(strChatID, 10, 64)
+	if err != nil {
+		c.String(http.StatusOK, err.Error())
+		return
+	}
+
+	// without year and month show month with latest activity
+	lang := getWebLanguage(c)
+	loc := s.getLocation(c, chatID)
+	month := time.Now().In(loc)
+	if last, ok := db.GetLastDate(chatID, loc); ok {
+		month = last
+	}
+	if strYear, strMonth := c.Param("year"), c.Param("month"); strYear != "" && strMonth != "" {
+		year, err := strconv.Atoi(strYear)
+		if err != nil {
+			c.String(http.StatusOK, err.Error())
+			return
+		}
+		m, err := strconv.Atoi(strMonth)
+		if err != nil {
+			c.String(http.StatusOK, err.Error())
+			return
+		}
+		if m < 1 || m > 12 {
+			c.String(http.StatusOK, i18n.T(lang, "web.wrong_month", m))
+			return
+		}
+		month = time.Date(year, time.Month(m), 1, 0, 0, 0, 0, loc)
+	}
+
+	page := parseTemplate(c, lang, s.getCalendar(lang, chatID, month.Year(), month.Month(), loc))
+	c.Header("X-XSS-Protection", "1; mode=block")
+	c.Data(http.StatusOK, "text/html", page)
+}
+
+func getCalendarLink(chatID int64, t time.Time) string {
+	return fmt.Sprintf("/chat/%d/calendar/%d/%d", chatID, t.Year(), t.Month())
+}
+
+func getDayLink(chatID int64, t time.Time) string {
+	return fmt.Sprintf("/chat/%d/%d/%d/%d", chatID, t.Year(), t.Month(), t.Day())
+}
+
+// formatMonth returns name of month with year in language
+func formatMonth(lang string, t time.Time) string {
+	return i18n.T(lang, "web.month", i18n.MonthName(lang, t.Month()), t.Year())
+}
+
+// getCalendarLevel returns activity level from 1 to calendarLevels for count relative to max
+func getCalendarLevel(count, max int) int {
+	if count <= 0 || max <= 0 {
+		return 0
+	}
+	return (count*calendarLevels + max - 1) / max
+}
+
+func (s *Server) getCalendar(lang string, chatID int64, year int, month time.Month, loc *time.Location) (body string) {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	counts, err := db.GetDayCounts(chatID, year, int(month), loc)
+	if err != nil {
+		log.Printf("Error in GetDayCounts for chat %d: %s", chatID, err)
+		return ""
+	}
+	max := 0
+	for _, count := range counts {
+		if count > max {
+			max = count
+		}
+	}
+
+	now := time.Now().In(loc)
+	body += getTimezoneInfo(lang, loc)
+	body += fmt.Sprintf(`
+	<p class="calendar-nav">
+		<a href="%s">&laquo; %s</a> |
+		<a href="%s">%s</a> |`, getCalendarLink(chatID, first.AddDate(0, -1, 0)), formatMonth(lang, first.AddDate(0, -1, 0)),
+		getCalendarLink(chatID, now), i18n.T(lang, "web.today"))
+	if last, ok := db.GetLastDate(chatID, loc); ok {
+		body += fmt.Sprintf(`
+		<a href="%s">%s</a> (<a href="%s">%s</a>) |`, getCalendarLink(chatID, last), i18n.T(lang, "web.latest"),
+			getDayLink(chatID, last), last.Format("02.01.2006"))
+	}
+	body += fmt.Sprintf(`
+		<a href="%s">%s &raquo;</a>
+	</p>`, getCalendarLink(chatID, first.AddDate(0, 1, 0)), formatMonth(lang, first.AddDate(0, 1, 0)))
+
+	body += fmt.Sprintf(`<table class="calendar"><caption>%s</caption>
+			<tr>`, formatMonth(lang, first))
+	// weeks begin on Monday
+	for i := 1; i <= 7; i++ {
+		body += fmt.Sprintf(`<th>%s</th>`, i18n.WeekdayName(lang, time.Weekday(i%7)))
+	}
+	body += `</tr>
+			<tr>`
+
+	offset := (int(first.Weekday()) + 6) % 7
+	for i := 0; i < offset; i++ {
+		body += `<td></td>`
+	}
+	for day := first; day.Month() == month; day = day.AddDate(0, 0, 1) {
+		if day != first && day.Weekday() == time.Monday {
+			body += `</tr>
+			<tr>`
+		}
+
+		class := ""
+		if day.Year() == now.Year() && day.YearDay() == now.YearDay() {
+			class = " today"
+		}
+		count := counts[day.Day()]
+		if count == 0 {
+			body += fmt.Sprintf(`<td class="day%s">%02d</td>`, class, day.Day())
+			continue
+		}
+		body += fmt.Sprintf(`<td class="day level%d%s" title="%d"><a href="%s">%02d</a><br/><small>%d</small></td>`,
+			getCalendarLevel(count, max), class, count, getDayLink(chatID, day), day.Day(), count)
+	}
+	body += `</tr>`
+	body += tableEnd
+
+	return
+}
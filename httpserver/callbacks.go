@@ -0,0 +1,91 @@
+package httpserver
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// CallbackHandler handles inline keyboard callback with data in format prefix:arg1:arg2...
+type CallbackHandler func(query *tgbotapi.CallbackQuery, args []string)
+
+// CallbackRouter is a registry of inline keyboard callbacks by data prefix, it is safe for concurrent use
+type CallbackRouter struct {
+	handlers map[string]CallbackHandler
+	mutex    sync.RWMutex
+}
+
+// Register adds callback handler for data prefix, it panics if prefix is already registered
+func (r *CallbackRouter) Register(prefix string, handler CallbackHandler) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.handlers == nil {
+		r.handlers = make(map[string]CallbackHandler)
+	}
+	if _, ok := r.handlers[prefix]; ok {
+		panic(fmt.Sprintf("callback %s already registered", prefix))
+	}
+	r.handlers[prefix] = handler
+}
+
+// Get returns callback handler for data prefix
+func (r *CallbackRouter) Get(prefix string) (handler CallbackHandler, ok bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	handler, ok = r.handlers[prefix]
+	return
+}
+
+// getCallbackData returns data for callback with prefix and arguments
+func getCallbackData(prefix string, args ...interface{}) string {
+	parts := []string{prefix}
+	for _, arg := range args {
+		parts = append(parts, fmt.Sprint(arg))
+	}
+	return strings.Join(parts, ":")
+}
+
+// CallbackQueryHandler function for handle inline keyboard callbacks
+func (s *Server) CallbackQueryHandler(query *tgbotapi.CallbackQuery) {
+	if query == nil || query.From == nil {
+		return
+	}
+	parts := strings.Split(query.Data, ":")
+	handler, ok := s.Callbacks.Get(parts[0])
+	if !ok {
+		log.Printf("Unknown callback: %s", query.Data)
+		s.answerCallback(query, "", false)
+		return
+	}
+	handler(query, parts[1:])
+}
+
+// answerCallback answers callback query with notification text or alert
+func (s *Server) answerCallback(query *tgbotapi.CallbackQuery, text string, alert bool) {
+	config := tgbotapi.NewCallback(query.ID, text)
+	config.ShowAlert = alert
+	if err := checkResponse(s.Bot.AnswerCallbackQuery(config)); err != nil {
+		log.Printf("Error in answerCallback: %s", err)
+	}
+}
+
+// sendKeyboard sends message with inline keyboard
+func (s *Server) sendKeyboard(msgText string, chatID int64, replyID int, keyboard tgbotapi.InlineKeyboardMarkup) (sent tgbotapi.Message, err error) {
+	msg := tgbotapi.NewMessage(chatID, msgText)
+	msg.ReplyMarkup = keyboard
+	if replyID != 0 {
+		msg.ReplyToMessageID = replyID
+	}
+	return s.Bot.Send(msg)
+}
+
+// InitCallbacks registers inline keyboard callbacks
+func (s *Server) InitCallbacks() {
+	s.Callbacks.Register(captchaPrefix, s.captchaCallback)
+	s.Callbacks.Register(reportPrefix, s.reportCallback)
+}
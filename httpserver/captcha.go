@@ -0,0 +1,291 @@
+package httpserver
+
+import (
+	"log"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+const (
+	captchaPrefix  = "captcha"
+	captchaOptions = 4
+)
+
+// newCaptcha returns question in language, answer and keyboard of captcha for user
+func newCaptcha(lang, mode string, user *tgbotapi.User, timeout time.Duration) (question, answer string, keyboard tgbotapi.InlineKeyboardMarkup) {
+	minutes := int(timeout / time.Minute)
+	if mode != db.CaptchaModeMath {
+		answer = "ok"
+		question = i18n.T(lang, "captcha.button_question", user.String(), minutes)
+		keyboard = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "captcha.button"), getCallbackData(captchaPrefix, user.ID, answer))))
+		return
+	}
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	a, b := r.Intn(10)+1, r.Intn(10)+1
+	answer = strconv.Itoa(a + b)
+	question = i18n.T(lang, "captcha.math_question", user.String(), a, b, minutes)
+
+	options := []int{a + b}
+	for len(options) < captchaOptions {
+		option := r.Intn(20) + 2
+		found := false
+		for _, o := range options {
+			found = found || o == option
+		}
+		if !found {
+			options = append(options, option)
+		}
+	}
+	var row []tgbotapi.InlineKeyboardButton
+	for _, i := range r.Perm(len(options)) {
+		option := strconv.Itoa(options[i])
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(option, getCallbackData(captchaPrefix, user.ID, option)))
+	}
+	keyboard = tgbotapi.NewInlineKeyboardMarkup(row)
+	return
+}
+
+// MembersHandler handles joined and left chat members
+func (s *Server) MembersHandler(msg *tgbotapi.Message) {
+	if msg.NewChatMembers == nil && msg.LeftChatMember == nil {
+		return
+	}
+	settings, err := db.GetChatSettings(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in MembersHandler -> GetChatSettings: %s", err)
+		return
+	}
+
+	if msg.NewChatMembers != nil {
+		for i := range *msg.NewChatMembers {
+			user := &(*msg.NewChatMembers)[i]
+			if user.IsBot {
+				continue
+			}
+			// challenged member is greeted after captcha
+			if settings.Captcha.Enabled && s.challenge(msg.Chat, user, settings.Captcha) {
+				continue
+			}
+			if err = s.sendGreeting(settings.Welcome, msg.Chat, user, 0); err != nil {
+				log.Printf("Error in MembersHandler -> sendGreeting: %s", err)
+			}
+		}
+	}
+	if user := msg.LeftChatMember; user != nil {
+		s.cancelChallenge(msg.Chat.ID, user.ID)
+		// members removed by the bot are not farewelled
+		if user.IsBot || (msg.From != nil && msg.From.ID == s.Bot.Self.ID) {
+			return
+		}
+		if err = s.sendGreeting(settings.Goodbye, msg.Chat, user, 0); err != nil {
+			log.Printf("Error in MembersHandler -> sendGreeting: %s", err)
+		}
+	}
+}
+
+// challenge restricts new member and sends captcha, it returns false if member isn't challenged,
+// trusted members are not checked
+func (s *Server) challenge(chat *tgbotapi.Chat, user *tgbotapi.User, settings db.CaptchaSettings) bool {
+	role, err := s.getUserRole(user.ID, chat)
+	if err != nil {
+		log.Printf("Error in challenge -> getUserRole: %s", err)
+		return false
+	}
+	if role >= RoleTrusted {
+		return false
+	}
+
+	// returning member may be muted already, the mute is kept after captcha
+	member, err := s.Bot.GetChatMember(tgbotapi.ChatConfigWithUser{ChatID: chat.ID, UserID: user.ID})
+	if err != nil {
+		log.Printf("Error in challenge -> GetChatMember: %s", err)
+		return false
+	}
+	muted := member.Status == "restricted" && !member.CanSendMessages
+	if !muted {
+		if err = s.restrictUser(chat.ID, user.ID, time.Time{}, false); err != nil {
+			log.Printf("Error in challenge -> restrictUser: %s", err)
+			return false
+		}
+	}
+	timeout := settings.GetTimeout()
+	question, answer, keyboard := newCaptcha(getLanguage(chat, nil), settings.GetMode(), user, timeout)
+	sent, err := s.sendKeyboard(question, chat.ID, 0, keyboard)
+	if err != nil {
+		log.Printf("Error in challenge -> sendKeyboard: %s", err)
+		// member has no captcha to pass, so restriction is lifted
+		if !muted {
+			if err = s.restrictUser(chat.ID, user.ID, time.Time{}, true); err != nil {
+				log.Printf("Error in challenge -> restrictUser: %s", err)
+			}
+		}
+		return false
+	}
+
+	now := time.Now()
+	c := &db.Challenge{
+		ChatID:    chat.ID,
+		UserID:    user.ID,
+		User:      user.String(),
+		MessageID: sent.MessageID,
+		Answer:    answer,
+		Muted:     muted,
+		Deadline:  now.Add(timeout).Unix(),
+		Date:      now.Unix(),
+	}
+	if err = db.SaveChallenge(c); err != nil {
+		log.Printf("Error in challenge -> SaveChallenge: %s", err)
+	}
+	return true
+}
+
+// captchaCallback checks answer of captcha, args are user ID and answer
+func (s *Server) captchaCallback(query *tgbotapi.CallbackQuery, args []string) {
+	if len(args) != 2 || query.Message == nil {
+		s.answerCallback(query, "", false)
+		return
+	}
+	lang := getLanguage(query.Message.Chat, query.From)
+	userID, err := strconv.Atoi(args[0])
+	if err != nil || userID != query.From.ID {
+		s.answerCallback(query, i18n.T(lang, "captcha.not_yours"), true)
+		return
+	}
+
+	c, err := db.GetChallenge(query.Message.Chat.ID, userID)
+	if err != nil {
+		log.Printf("Error in captchaCallback -> GetChallenge: %s", err)
+		s.answerCallback(query, i18n.T(lang, "captcha.check_failed"), true)
+		return
+	}
+	if c == nil {
+		s.answerCallback(query, i18n.T(lang, "captcha.finished"), false)
+		return
+	}
+	if args[1] != c.Answer {
+		s.answerCallback(query, i18n.T(lang, "captcha.wrong"), true)
+		s.failChallenge(c, "captcha.wrong_reason")
+		return
+	}
+
+	if !c.Muted {
+		if err = s.restrictUser(c.ChatID, c.UserID, time.Time{}, true); err != nil {
+			log.Printf("Error in captchaCallback -> restrictUser: %s", err)
+			s.answerCallback(query, i18n.T(lang, "captcha.unrestrict_failed"), true)
+			return
+		}
+	}
+	s.answerCallback(query, i18n.T(lang, "captcha.passed"), false)
+	s.cancelChallenge(c.ChatID, c.UserID)
+
+	settings, err := db.GetChatSettings(c.ChatID)
+	if err != nil {
+		log.Printf("Error in captchaCallback -> GetChatSettings: %s", err)
+		return
+	}
+	if err = s.sendGreeting(settings.Welcome, query.Message.Chat, query.From, 0); err != nil {
+		log.Printf("Error in captchaCallback -> sendGreeting: %s", err)
+	}
+}
+
+// failChallenge removes user who didn't pass captcha from chat, the user can join again,
+// reason is a catalog key
+func (s *Server) failChallenge(c *db.Challenge, reason string) {
+	if err := s.kickOut(c.ChatID, c.UserID); err != nil {
+		log.Printf("Error in failChallenge -> kickOut: %s", err)
+	}
+	s.cancelChallenge(c.ChatID, c.UserID)
+	lang := getChatLanguage(c.ChatID)
+	s.SendMessage(i18n.T(lang, "captcha.failed", c.User, i18n.T(lang, reason)), c.ChatID, 0)
+}
+
+// cancelChallenge removes pending captcha of user and message with it
+func (s *Server) cancelChallenge(chatID int64, userID int) {
+	c, err := db.GetChallenge(chatID, userID)
+	if err != nil {
+		log.Printf("Error in cancelChallenge -> GetChallenge: %s", err)
+		return
+	}
+	if c == nil {
+		return
+	}
+	if err = db.RemoveChallenge(chatID, userID); err != nil {
+		log.Printf("Error in cancelChallenge -> RemoveChallenge: %s", err)
+	}
+	if c.MessageID != 0 {
+		if err = s.deleteMessage(chatID, c.MessageID); err != nil {
+			log.Printf("Error in cancelChallenge -> deleteMessage: %s", err)
+		}
+	}
+}
+
+// expireChallenges removes users who didn't pass captcha in time,
+// captchas expired while bot was stopped are handled at start
+func (s *Server) expireChallenges() {
+	challenges, err := db.GetExpiredChallenges(time.Now())
+	if err != nil {
+		log.Printf("Error in GetExpiredChallenges: %s", err)
+		return
+	}
+	for _, c := range challenges {
+		s.failChallenge(c, "captcha.timeout_reason")
+	}
+}
+
+// Captcha command shows or changes captcha settings of chat
+func (s *Server) Captcha(msg *tgbotapi.Message) {
+	args := strings.Fields(strings.ToLower(msg.CommandArguments()))
+
+	settings, err := db.GetChatSettings(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in Captcha -> GetChatSettings: %s", err)
+		return
+	}
+
+	lang := getMessageLanguage(msg)
+	if len(args) > 0 {
+		if !s.checkRole(msg, RoleAdmin) {
+			return
+		}
+
+		switch {
+		case args[0] == "on" && len(args) == 1:
+			settings.Captcha.Enabled = true
+		case args[0] == "off" && len(args) == 1:
+			settings.Captcha.Enabled = false
+		case args[0] == "mode" && len(args) == 2 && (args[1] == db.CaptchaModeButton || args[1] == db.CaptchaModeMath):
+			settings.Captcha.Mode = args[1]
+		case args[0] == "timeout" && len(args) == 2:
+			minutes, err := strconv.Atoi(args[1])
+			if err != nil || minutes <= 0 {
+				s.SendError(i18n.T(lang, "captcha.usage"), msg)
+				return
+			}
+			settings.Captcha.Timeout = minutes
+		default:
+			s.SendError(i18n.T(lang, "captcha.usage"), msg)
+			return
+		}
+
+		if err = db.SaveChatSettings(settings); err != nil {
+			log.Printf("Error in Captcha -> SaveChatSettings: %s", err)
+			return
+		}
+	}
+
+	state := i18n.T(lang, "captcha.off")
+	if settings.Captcha.Enabled {
+		state = i18n.T(lang, "captcha.on")
+	}
+	s.SendMessage(i18n.T(lang, "captcha.description", state, i18n.T(lang, "captcha.mode."+settings.Captcha.GetMode()),
+		int(settings.Captcha.GetTimeout()/time.Minute)), msg.Chat.ID, msg.MessageID)
+}
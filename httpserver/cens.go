@@ -0,0 +1,234 @@
+package httpserver
+
+import (
+	"log"
+	"strings"
+
+	"github.com/elemc/gotelegrambot/censor"
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// defaultCensDictionary is a name of dictionary loaded from mat.txt, it is used by chats without own dictionaries
+const defaultCensDictionary = "mat"
+
+var censExemptRoles = map[string]Role{
+	db.CensExemptTrusted:   RoleTrusted,
+	db.CensExemptModerator: RoleModerator,
+	db.CensExemptAdmin:     RoleAdmin,
+}
+
+// getCensDictionaries returns names of dictionaries used in chat
+func getCensDictionaries(settings db.CensSettings) []string {
+	if len(settings.Dictionaries) == 0 {
+		return []string{defaultCensDictionary}
+	}
+	return settings.Dictionaries
+}
+
+// getCensFilter returns filter compiled from chat dictionaries and chat words
+func (s *Server) getCensFilter(chatID int64, settings db.CensSettings) (filter *censor.Filter, err error) {
+	if filter, ok := s.CensFilters.Get(chatID); ok {
+		return filter, nil
+	}
+
+	var entries []string
+	for _, name := range getCensDictionaries(settings) {
+		words, ok := s.CensList.Words(name)
+		if !ok {
+			log.Printf("Cens dictionary %s of chat %d not found", name, chatID)
+			continue
+		}
+		entries = append(entries, words...)
+	}
+	chatWords, err := db.GetCensWords(chatID)
+	if err != nil {
+		return
+	}
+	entries = append(entries, chatWords.Words...)
+
+	if filter, err = censor.New(entries); err != nil {
+		return
+	}
+	s.CensFilters.Set(chatID, filter)
+	return
+}
+
+// isCensExempt returns true if message author isn't checked by cens
+func (s *Server) isCensExempt(msg *tgbotapi.Message, settings db.CensSettings) bool {
+	exempt, ok := censExemptRoles[settings.GetExemptRole()]
+	if !ok {
+		return false
+	}
+	role, err := s.getUserRole(msg.From.ID, msg.Chat)
+	if err != nil {
+		log.Printf("Error in isCensExempt -> getUserRole: %s", err)
+		return false
+	}
+	return role >= exempt
+}
+
+// getCensDescription returns human readable cens settings
+func getCensDescription(lang string, settings db.CensSettings) string {
+	state := i18n.T(lang, "cens.off")
+	if settings.Enabled {
+		state = i18n.T(lang, "cens.on")
+	}
+	action := i18n.T(lang, "cens.action."+settings.GetAction())
+	exempt := i18n.T(lang, "none")
+	if role, ok := censExemptRoles[settings.GetExemptRole()]; ok {
+		exempt = i18n.T(lang, "cens.exempt_role", role.Name(lang))
+	}
+	return i18n.T(lang, "cens.description", state, action, strings.Join(getCensDictionaries(settings), ", "), exempt)
+}
+
+// CensSettings command shows or changes cens settings of chat
+func (s *Server) CensSettings(msg *tgbotapi.Message) {
+	args := strings.Fields(strings.ToLower(msg.CommandArguments()))
+
+	settings, err := db.GetChatSettings(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in CensSettings -> GetChatSettings: %s", err)
+		return
+	}
+
+	lang := getMessageLanguage(msg)
+	if len(args) == 0 {
+		s.SendMessage(getCensDescription(lang, settings.Cens), msg.Chat.ID, msg.MessageID)
+		return
+	}
+
+	if !s.checkRole(msg, RoleAdmin) {
+		return
+	}
+
+	switch {
+	case args[0] == "on" && len(args) == 1:
+		settings.Cens.Enabled = true
+	case args[0] == "off" && len(args) == 1:
+		settings.Cens.Enabled = false
+	case args[0] == "action" && len(args) == 2:
+		switch args[1] {
+		case db.CensActionReply, db.CensActionDelete, db.CensActionCount:
+			settings.Cens.Action = args[1]
+		default:
+			s.SendError(i18n.T(lang, "cens.usage"), msg)
+			return
+		}
+	case args[0] == "exempt" && len(args) == 2:
+		if _, ok := censExemptRoles[args[1]]; !ok && args[1] != db.CensExemptNone {
+			s.SendError(i18n.T(lang, "cens.usage"), msg)
+			return
+		}
+		settings.Cens.ExemptRole = args[1]
+	case args[0] == "dict" && len(args) > 1:
+		if len(args) == 2 && args[1] == "default" {
+			settings.Cens.Dictionaries = nil
+			break
+		}
+		for _, name := range args[1:] {
+			if _, ok := s.CensList.Words(name); !ok {
+				s.SendError(i18n.T(lang, "cens.dict_not_found", name, strings.Join(s.CensList.Names(), ", ")), msg)
+				return
+			}
+		}
+		settings.Cens.Dictionaries = args[1:]
+	default:
+		s.SendError(i18n.T(lang, "cens.usage"), msg)
+		return
+	}
+
+	if err = db.SaveChatSettings(settings); err != nil {
+		log.Printf("Error in CensSettings -> SaveChatSettings: %s", err)
+		return
+	}
+	s.CensFilters.Remove(msg.Chat.ID)
+	s.SendMessage(getCensDescription(lang, settings.Cens), msg.Chat.ID, msg.MessageID)
+}
+
+// CensWord command adds words to chat dictionary or removes them
+func (s *Server) CensWord(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	lang := getMessageLanguage(msg)
+	if len(args) < 2 || (args[0] != "add" && args[0] != "remove") {
+		s.SendError(i18n.T(lang, "censword.usage"), msg)
+		return
+	}
+
+	words, err := db.GetCensWords(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in CensWord -> GetCensWords: %s", err)
+		return
+	}
+
+	var changed []string
+	for _, word := range args[1:] {
+		i := indexOfWord(words.Words, word)
+		if args[0] == "add" {
+			if i != -1 {
+				continue
+			}
+			if _, err = censor.New([]string{word}); err != nil {
+				s.SendError(i18n.T(lang, "censword.invalid", err), msg)
+				return
+			}
+			words.Words = append(words.Words, word)
+		} else {
+			if i == -1 {
+				continue
+			}
+			words.Words = append(words.Words[:i], words.Words[i+1:]...)
+		}
+		changed = append(changed, word)
+	}
+	if len(changed) == 0 {
+		s.SendError(i18n.T(lang, "censword.unchanged"), msg)
+		return
+	}
+
+	if err = db.SaveCensWords(words); err != nil {
+		log.Printf("Error in CensWord -> SaveCensWords: %s", err)
+		return
+	}
+	s.CensFilters.Remove(msg.Chat.ID)
+	if args[0] == "add" {
+		s.SendError(i18n.T(lang, "censword.added", strings.Join(changed, ", ")), msg)
+	} else {
+		s.SendError(i18n.T(lang, "censword.removed", strings.Join(changed, ", ")), msg)
+	}
+}
+
+// CensWordList command shows chat dictionaries and words added to them
+func (s *Server) CensWordList(msg *tgbotapi.Message) {
+	settings, err := db.GetChatSettings(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in CensWordList -> GetChatSettings: %s", err)
+		return
+	}
+	words, err := db.GetCensWords(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in CensWordList -> GetCensWords: %s", err)
+		return
+	}
+
+	lang := getMessageLanguage(msg)
+	text := i18n.T(lang, "censlist.dictionaries",
+		strings.Join(getCensDictionaries(settings.Cens), ", "), strings.Join(s.CensList.Names(), ", "))
+	if len(words.Words) == 0 {
+		text += i18n.T(lang, "censlist.no_words")
+	} else {
+		text += i18n.T(lang, "censlist.words", strings.Join(words.Words, ", "))
+	}
+	s.SendMessage(text, msg.Chat.ID, msg.MessageID)
+}
+
+func indexOfWord(words []string, word string) int {
+	for i, w := range words {
+		if w == word {
+			return i
+		}
+	}
+	return -1
+}
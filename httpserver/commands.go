@@ -0,0 +1,509 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// Role is a user role in chat
+type Role int
+
+// Roles from the least to the most privileged
+const (
+	RoleMember Role = iota
+	RoleTrusted
+	RoleModerator
+	RoleAdmin
+	RoleOwner
+)
+
+// Chat types for commands
+const (
+	ChatPrivate    = "private"
+	ChatGroup      = "group"
+	ChatSuperGroup = "supergroup"
+)
+
+// Command describes bot command
+type Command struct {
+	Name        string
+	Aliases     []string
+	Args        string // catalog key of arguments syntax
+	Description string // catalog key of description
+	Role        Role
+	ChatTypes   []string // empty means any chat
+	Handler     func(msg *tgbotapi.Message)
+}
+
+// CommandRouter is a registry of bot commands, it is safe for concurrent use
+type CommandRouter struct {
+	commands []*Command
+	byName   map[string]*Command
+	mutex    sync.RWMutex
+}
+
+type botCommand struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+// Name returns role name in language
+func (role Role) Name(lang string) string {
+	switch role {
+	case RoleTrusted:
+		return i18n.T(lang, "role.trusted")
+	case RoleModerator:
+		return i18n.T(lang, "role.moderator")
+	case RoleAdmin:
+		return i18n.T(lang, "role.admin")
+	case RoleOwner:
+		return i18n.T(lang, "role.owner")
+	}
+	return i18n.T(lang, "role.member")
+}
+
+// AllowedIn returns true if command is allowed in chat
+func (cmd *Command) AllowedIn(chat *tgbotapi.Chat) bool {
+	if len(cmd.ChatTypes) == 0 {
+		return true
+	}
+	for _, chatType := range cmd.ChatTypes {
+		if chatType == chat.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// Usage returns command with arguments syntax in language
+func (cmd *Command) Usage(lang string) string {
+	if cmd.Args == "" {
+		return "/" + cmd.Name
+	}
+	return fmt.Sprintf("/%s %s", cmd.Name, i18n.T(lang, cmd.Args))
+}
+
+// Register adds command to registry, it panics if name or alias is already registered
+func (r *CommandRouter) Register(cmd *Command) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.byName == nil {
+		r.byName = make(map[string]*Command)
+	}
+	for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+		if _, ok := r.byName[name]; ok {
+			panic(fmt.Sprintf("command %s already registered", name))
+		}
+		r.byName[name] = cmd
+	}
+	r.commands = append(r.commands, cmd)
+}
+
+// Get returns command by name or alias
+func (r *CommandRouter) Get(name string) (cmd *Command, ok bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	cmd, ok = r.byName[strings.ToLower(name)]
+	return
+}
+
+// Commands returns registered commands in registration order
+func (r *CommandRouter) Commands() []*Command {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]*Command, len(r.commands))
+	copy(result, r.commands)
+	return result
+}
+
+// InitCommands registers bot commands and publishes them to Telegram
+func (s *Server) InitCommands() {
+	s.Commands.Register(&Command{
+		Name:        "start",
+		Description: "cmd.start.desc",
+		Handler: func(msg *tgbotapi.Message) {
+			s.SendMessage(i18n.T(getMessageLanguage(msg), "start.hello"), msg.Chat.ID, msg.MessageID)
+		},
+	})
+	s.Commands.Register(&Command{
+		Name:        "help",
+		Description: "cmd.help.desc",
+		Handler:     s.SendHelp,
+	})
+	s.Commands.Register(&Command{
+		Name:        "ping",
+		Description: "cmd.ping.desc",
+		Handler:     s.SendPing,
+	})
+	s.Commands.Register(&Command{
+		Name:        "ban",
+		Args:        "cmd.ban.args",
+		Description: "cmd.ban.desc",
+		Role:        RoleModerator,
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler: func(msg *tgbotapi.Message) {
+			s.BanUnbanUser(msg, true)
+		},
+	})
+	s.Commands.Register(&Command{
+		Name:        "unban",
+		Args:        "cmd.unban.args",
+		Description: "cmd.unban.desc",
+		Role:        RoleModerator,
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler: func(msg *tgbotapi.Message) {
+			s.BanUnbanUser(msg, false)
+		},
+	})
+	s.Commands.Register(&Command{
+		Name:        "tempban",
+		Args:        "cmd.tempban.args",
+		Description: "cmd.tempban.desc",
+		Role:        RoleModerator,
+		ChatTypes:   []string{ChatSuperGroup},
+		Handler:     s.TempBan,
+	})
+	s.Commands.Register(&Command{
+		Name:        "mute",
+		Args:        "cmd.mute.args",
+		Description: "cmd.mute.desc",
+		Role:        RoleModerator,
+		ChatTypes:   []string{ChatSuperGroup},
+		Handler:     s.Mute,
+	})
+	s.Commands.Register(&Command{
+		Name:        "readonly",
+		Args:        "cmd.readonly.args",
+		Description: "cmd.readonly.desc",
+		Role:        RoleModerator,
+		ChatTypes:   []string{ChatSuperGroup},
+		Handler:     s.ReadOnly,
+	})
+	s.Commands.Register(&Command{
+		Name:        "unmute",
+		Args:        "cmd.unmute.args",
+		Description: "cmd.unmute.desc",
+		Role:        RoleModerator,
+		ChatTypes:   []string{ChatSuperGroup},
+		Handler:     s.Unmute,
+	})
+	s.Commands.Register(&Command{
+		Name:        "banlist",
+		Description: "cmd.banlist.desc",
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.BanList,
+	})
+	s.Commands.Register(&Command{
+		Name:        "clearcens",
+		Args:        "cmd.clearcens.args",
+		Description: "cmd.clearcens.desc",
+		Role:        RoleModerator,
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.ClearCens,
+	})
+	s.Commands.Register(&Command{
+		Name:        "mycens",
+		Description: "cmd.mycens.desc",
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.GetCensLevel,
+	})
+	s.Commands.Register(&Command{
+		Name:        "cens",
+		Args:        "cmd.cens.args",
+		Description: "cmd.cens.desc",
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.CensSettings,
+	})
+	s.Commands.Register(&Command{
+		Name:        "censword",
+		Args:        "cmd.censword.args",
+		Description: "cmd.censword.desc",
+		Role:        RoleAdmin,
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.CensWord,
+	})
+	s.Commands.Register(&Command{
+		Name:        "censlist",
+		Description: "cmd.censlist.desc",
+		Role:        RoleModerator,
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.CensWordList,
+	})
+	s.Commands.Register(&Command{
+		Name:        "flood",
+		Args:        "cmd.flood.args",
+		Description: "cmd.flood.desc",
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.Flood,
+	})
+	s.Commands.Register(&Command{
+		Name:        "spam",
+		Args:        "cmd.spam.args",
+		Description: "cmd.spam.desc",
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.Spam,
+	})
+	s.Commands.Register(&Command{
+		Name:        "spamlog",
+		Args:        "cmd.spamlog.args",
+		Description: "cmd.spamlog.desc",
+		Role:        RoleModerator,
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.SpamLog,
+	})
+	s.Commands.Register(&Command{
+		Name:        "captcha",
+		Args:        "cmd.captcha.args",
+		Description: "cmd.captcha.desc",
+		ChatTypes:   []string{ChatSuperGroup},
+		Handler:     s.Captcha,
+	})
+	s.Commands.Register(&Command{
+		Name:        "setwelcome",
+		Args:        "cmd.setwelcome.args",
+		Description: "cmd.setwelcome.desc",
+		Role:        RoleAdmin,
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.SetWelcome,
+	})
+	s.Commands.Register(&Command{
+		Name:        "setgoodbye",
+		Args:        "cmd.setgoodbye.args",
+		Description: "cmd.setgoodbye.desc",
+		Role:        RoleAdmin,
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.SetGoodbye,
+	})
+	s.Commands.Register(&Command{
+		Name:        "report",
+		Args:        "cmd.report.args",
+		Description: "cmd.report.desc",
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.Report,
+	})
+	s.Commands.Register(&Command{
+		Name:        "reportchat",
+		Args:        "cmd.reportchat.args",
+		Description: "cmd.reportchat.desc",
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.ReportChat,
+	})
+	s.Commands.Register(&Command{
+		Name:        "warn",
+		Args:        "cmd.warn.args",
+		Description: "cmd.warn.desc",
+		Role:        RoleModerator,
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.WarnAdd,
+	})
+	s.Commands.Register(&Command{
+		Name:        "clearwarn",
+		Args:        "cmd.clearwarn.args",
+		Description: "cmd.clearwarn.desc",
+		Role:        RoleModerator,
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.WarnClear,
+	})
+	s.Commands.Register(&Command{
+		Name:        "mywarn",
+		Description: "cmd.mywarn.desc",
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.GetWarnLevel,
+	})
+	s.Commands.Register(&Command{
+		Name:        "warns",
+		Args:        "cmd.warns.args",
+		Description: "cmd.warns.desc",
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.Warns,
+	})
+	s.Commands.Register(&Command{
+		Name:        "unwarn",
+		Args:        "cmd.unwarn.args",
+		Description: "cmd.unwarn.desc",
+		Role:        RoleModerator,
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.Unwarn,
+	})
+	s.Commands.Register(&Command{
+		Name:        "warnset",
+		Args:        "cmd.warnset.args",
+		Description: "cmd.warnset.desc",
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.WarnSettings,
+	})
+	s.Commands.Register(&Command{
+		Name:        "policy",
+		Args:        "cmd.policy.args",
+		Description: "cmd.policy.desc",
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.Policy,
+	})
+	s.Commands.Register(&Command{
+		Name:        "promote",
+		Args:        "cmd.promote.args",
+		Description: "cmd.promote.desc",
+		Role:        RoleAdmin,
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.Promote,
+	})
+	s.Commands.Register(&Command{
+		Name:        "demote",
+		Args:        "cmd.demote.args",
+		Description: "cmd.demote.desc",
+		Role:        RoleAdmin,
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.Demote,
+	})
+	s.Commands.Register(&Command{
+		Name:        "roles",
+		Description: "cmd.roles.desc",
+		ChatTypes:   []string{ChatGroup, ChatSuperGroup},
+		Handler:     s.Roles,
+	})
+	s.Commands.Register(&Command{
+		Name:        "timezone",
+		Aliases:     []string{"tz"},
+		Args:        "cmd.timezone.args",
+		Description: "cmd.timezone.desc",
+		Handler:     s.Timezone,
+	})
+	s.Commands.Register(&Command{
+		Name:        "lang",
+		Args:        "cmd.lang.args",
+		Description: "cmd.lang.desc",
+		Handler:     s.Lang,
+	})
+	s.Commands.Register(&Command{
+		Name:        "archive",
+		Args:        "cmd.archive.args",
+		Description: "cmd.archive.desc",
+		Handler:     s.Archive,
+	})
+
+	s.publishCommands()
+}
+
+// publishCommands sends commands list to Telegram for commands menu in every language,
+// list in default language is shown to users with other languages
+func (s *Server) publishCommands() {
+	for _, lang := range i18n.Languages() {
+		var list []botCommand
+		for _, cmd := range s.Commands.Commands() {
+			list = append(list, botCommand{Command: cmd.Name, Description: i18n.T(lang, cmd.Description)})
+		}
+		data, err := json.Marshal(list)
+		if err != nil {
+			log.Printf("Error in marshal commands: %s", err)
+			return
+		}
+
+		v := url.Values{}
+		v.Add("commands", string(data))
+		if lang != i18n.DefaultLanguage {
+			v.Add("language_code", lang)
+		}
+		if _, err = s.Bot.MakeRequest("setMyCommands", v); err != nil {
+			log.Printf("Error in setMyCommands for language %s: %s", lang, err)
+		}
+	}
+}
+
+// parseCommand returns command name and bot username from command with at name syntax
+func parseCommand(msg *tgbotapi.Message) (name, botName string) {
+	name = msg.CommandWithAt()
+	if i := strings.Index(name, "@"); i != -1 {
+		name, botName = name[:i], name[i+1:]
+	}
+	return
+}
+
+// CommandHandler function for handle commands for bot
+func (s *Server) CommandHandler(msg *tgbotapi.Message) {
+	if msg == nil {
+		return
+	}
+	name, botName := parseCommand(msg)
+	if botName != "" && !strings.EqualFold(botName, s.Bot.Self.UserName) {
+		// command for other bot
+		return
+	}
+	cmd, ok := s.Commands.Get(name)
+	if !ok {
+		log.Printf("Unknown command: %s", name)
+		return
+	}
+	if !cmd.AllowedIn(msg.Chat) {
+		s.SendError(i18n.T(getMessageLanguage(msg), "command.unavailable", cmd.Name), msg)
+		return
+	}
+	if !s.checkRole(msg, cmd.Role) {
+		return
+	}
+	cmd.Handler(msg)
+}
+
+// checkRole returns true if message author has role in chat or higher, error message is sent otherwise
+func (s *Server) checkRole(msg *tgbotapi.Message, role Role) bool {
+	if role == RoleMember {
+		return true
+	}
+	userRole, err := s.getUserRole(msg.From.ID, msg.Chat)
+	if err != nil {
+		log.Printf("Error in checkRole -> getUserRole: %s", err)
+		s.SendError(i18n.T(getMessageLanguage(msg), "role.own_unknown"), msg)
+		return false
+	}
+	if userRole < role {
+		lang := getMessageLanguage(msg)
+		s.SendError(i18n.T(lang, "command.role_required", role.Name(lang)), msg)
+		return false
+	}
+	return true
+}
+
+// SendHelp sends help message to chat
+func (s *Server) SendHelp(msg *tgbotapi.Message) {
+	lang := getMessageLanguage(msg)
+	helpMsg := i18n.T(lang, "help.title")
+	for _, cmd := range s.Commands.Commands() {
+		if !cmd.AllowedIn(msg.Chat) {
+			continue
+		}
+		line := fmt.Sprintf("%s - %s", cmd.Usage(lang), i18n.T(lang, cmd.Description))
+		if len(cmd.Aliases) > 0 {
+			line += i18n.T(lang, "help.aliases", strings.Join(cmd.Aliases, ", /"))
+		}
+		if cmd.Role > RoleMember {
+			line += fmt.Sprintf(" [%s]", cmd.Role.Name(lang))
+		}
+		helpMsg += "\n" + line
+	}
+	s.SendMessage(helpMsg, msg.Chat.ID, msg.MessageID)
+}
+
+// EditedCommandHandler handles command in edited message if the command was added or changed by edit,
+// it must be called before edited message is saved
+func (s *Server) EditedCommandHandler(msg *tgbotapi.Message) {
+	if msg == nil || !msg.IsCommand() {
+		return
+	}
+	old, err := db.GetMessage(msg.Chat.ID, msg.MessageID)
+	if err == nil && old.IsCommand() && old.CommandWithAt() == msg.CommandWithAt() && old.CommandArguments() == msg.CommandArguments() {
+		// command already handled
+		return
+	}
+	s.CommandHandler(msg)
+}
@@ -0,0 +1,328 @@
+package httpserver
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+const (
+	feedDays        = 7
+	feedMaxEntries  = 100
+	feedTitleLength = 80
+)
+
+type feedEntry struct {
+	ID      string
+	Title   string
+	Author  string
+	Updated time.Time
+	Content string
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Author  *atomAuthor `xml:"author,omitempty"`
+	Content atomContent `xml:"content"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description"`
+}
+
+func (s *Server) atomFeedPage(c *gin.Context) {
+	s.feedPage(c, "atom")
+}
+
+func (s *Server) rssFeedPage(c *gin.Context) {
+	s.feedPage(c, "rss")
+}
+
+func (s *Server) feedPage(c *gin.Context, format string) {
+	strChatID := c.Param("chat_id")
+	chatID, err := strconv.ParseInt(strChatID, 10, 64)
+	if err != nil {
+		c.String(http.StatusOK, err.Error())
+		return
+	}
+
+	lang := getWebLanguage(c)
+	chat, err := db.GetChat(chatID)
+	if err != nil {
+		log.Printf("Error in GetChat for chat %d: %s", chatID, err)
+		c.String(http.StatusNotFound, i18n.T(lang, "web.chat_not_found"))
+		return
+	}
+	settings, err := db.GetChatSettings(chatID)
+	if err != nil {
+		log.Printf("Error in GetChatSettings for chat %d: %s", chatID, err)
+		c.String(http.StatusInternalServerError, i18n.T(lang, "web.settings_unavailable"))
+		return
+	}
+	mode := c.DefaultQuery("mode", settings.FeedMode)
+
+	loc := db.GetChatLocation(chatID)
+	endTime := time.Now().In(loc)
+	beginTime := endTime.AddDate(0, 0, -feedDays)
+	msgs, err := db.GetMessagesByDate(chatID, beginTime, endTime)
+	if err != nil {
+		log.Printf("Error in GetMessagesByDate for chat %d: %s", chatID, err)
+		c.String(http.StatusInternalServerError, i18n.T(lang, "web.messages_unavailable"))
+		return
+	}
+
+	marks, err := db.GetModeratedMessages(chatID, beginTime, endTime)
+	if err != nil {
+		log.Printf("Error in GetModeratedMessages for chat %d: %s", chatID, err)
+		c.String(http.StatusInternalServerError, i18n.T(lang, "web.messages_unavailable"))
+		return
+	}
+	msgs = removeModerated(msgs, marks)
+
+	baseURL := s.getBaseURL(c)
+	var entries []feedEntry
+	if mode == db.FeedModeDay {
+		entries = getDayFeedEntries(lang, baseURL, chatID, msgs, loc)
+	} else {
+		entries = getMessageFeedEntries(lang, baseURL, chatID, msgs, loc)
+	}
+
+	title := getChatName(chat)
+	chatLink := fmt.Sprintf("%s/chat/%d/", baseURL, chatID)
+	updated := beginTime
+	if len(entries) > 0 {
+		updated = entries[0].Updated
+	}
+
+	var feed interface{}
+	contentType := "application/atom+xml; charset=utf-8"
+	if format == "rss" {
+		contentType = "application/rss+xml; charset=utf-8"
+		channel := rssChannel{Title: title, Link: chatLink, Description: i18n.T(lang, "web.feed_description", title)}
+		for _, entry := range entries {
+			channel.Items = append(channel.Items, rssItem{
+				Title:       entry.Title,
+				Link:        entry.ID,
+				GUID:        rssGUID{IsPermaLink: "true", Value: entry.ID},
+				PubDate:     entry.Updated.Format(time.RFC1123Z),
+				Description: entry.Content,
+			})
+		}
+		feed = rssFeed{Version: "2.0", Channel: channel}
+	} else {
+		atom := atomFeed{
+			Title:   title,
+			ID:      chatLink,
+			Updated: updated.Format(time.RFC3339),
+			Links: []atomLink{
+				{Href: chatLink},
+				{Href: baseURL + c.Request.URL.RequestURI(), Rel: "self"},
+			},
+		}
+		for _, entry := range entries {
+			e := atomEntry{
+				Title:   entry.Title,
+				ID:      entry.ID,
+				Updated: entry.Updated.Format(time.RFC3339),
+				Link:    atomLink{Href: entry.ID},
+				Content: atomContent{Type: "html", Body: entry.Content},
+			}
+			if entry.Author != "" {
+				e.Author = &atomAuthor{Name: entry.Author}
+			}
+			atom.Entries = append(atom.Entries, e)
+		}
+		feed = atom
+	}
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		log.Printf("Error in marshal feed for chat %d: %s", chatID, err)
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Data(http.StatusOK, contentType, append([]byte(xml.Header), data...))
+}
+
+// removeModerated returns messages without messages removed by moderation
+func removeModerated(msgs []*tgbotapi.Message, marks map[int]*db.ModeratedMessage) (result []*tgbotapi.Message) {
+	for _, msg := range msgs {
+		if marks[msg.MessageID] == nil {
+			result = append(result, msg)
+		}
+	}
+	return
+}
+
+// getBaseURL returns URL of web archive for absolute links
+func (s *Server) getBaseURL(c *gin.Context) string {
+	if s.BaseURL != "" {
+		return strings.TrimRight(s.BaseURL, "/")
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, c.Request.Host)
+}
+
+// getMessageLink returns permalink for message, the linked page begins with the message,
+// so the message is found on days with several pages of messages
+func getMessageLink(baseURL string, chatID int64, msg *tgbotapi.Message, loc *time.Location) string {
+	t := time.Unix(int64(msg.Date), 0).In(loc)
+	// cursor of position just before the message, messages are ordered by date and message ID
+	after := db.MessageCursor{Date: int64(msg.Date), MessageID: msg.MessageID - 1}
+	return fmt.Sprintf("%s%s?after=%s#msg%d", baseURL, getDayLink(chatID, t), after, msg.MessageID)
+}
+
+// getMessageSummary returns short plain text description of message in language
+func getMessageSummary(lang string, msg *tgbotapi.Message) string {
+	switch {
+	case msg.Text != "":
+		return msg.Text
+	case msg.Photo != nil:
+		return i18n.T(lang, "web.photo")
+	case msg.Sticker != nil:
+		return i18n.T(lang, "web.sticker")
+	case msg.Audio != nil:
+		return i18n.T(lang, "web.audio")
+	case msg.Document != nil:
+		return i18n.T(lang, "web.document")
+	case msg.Video != nil:
+		return i18n.T(lang, "web.video")
+	case msg.Voice != nil:
+		return i18n.T(lang, "web.voice")
+	}
+	return i18n.T(lang, "web.message")
+}
+
+func truncateText(text string, length int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:length]) + "..."
+}
+
+func getMessageFeedEntries(lang, baseURL string, chatID int64, msgs []*tgbotapi.Message, loc *time.Location) (entries []feedEntry) {
+	// newest messages first
+	for i := len(msgs) - 1; i >= 0 && len(entries) < feedMaxEntries; i-- {
+		msg := msgs[i]
+		author := ""
+		if msg.From != nil {
+			author = msg.From.String()
+		}
+		summary := getMessageSummary(lang, msg)
+		entries = append(entries, feedEntry{
+			ID:      getMessageLink(baseURL, chatID, msg, loc),
+			Title:   truncateText(fmt.Sprintf("%s: %s", author, summary), feedTitleLength),
+			Author:  author,
+			Updated: time.Unix(int64(msg.Date), 0).In(loc),
+			Content: fmt.Sprintf("<p>%s</p>", formatMessage(summary)),
+		})
+	}
+	return
+}
+
+func getDayFeedEntries(lang, baseURL string, chatID int64, msgs []*tgbotapi.Message, loc *time.Location) (entries []feedEntry) {
+	var (
+		day     string
+		content string
+		count   int
+		last    time.Time
+	)
+	flush := func() {
+		if count == 0 {
+			return
+		}
+		dayTime := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, loc)
+		entries = append(entries, feedEntry{
+			ID:      baseURL + getDayLink(chatID, dayTime),
+			Title:   fmt.Sprintf("%s: %s", day, i18n.N(lang, "messages", count, count)),
+			Updated: last,
+			Content: content,
+		})
+	}
+
+	for _, msg := range msgs {
+		t := time.Unix(int64(msg.Date), 0).In(loc)
+		if d := t.Format("02.01.2006"); d != day {
+			flush()
+			day, content, count = d, "", 0
+		}
+		author := ""
+		if msg.From != nil {
+			author = msg.From.String()
+		}
+		content += fmt.Sprintf(`<p>%s <strong>%s</strong>: %s</p>`, t.Format("15:04:05"), formatMessage(author), formatMessage(getMessageSummary(lang, msg)))
+		count++
+		last = t
+	}
+	flush()
+
+	// newest days first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return
+}
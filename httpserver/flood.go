@@ -0,0 +1,178 @@
+package httpserver
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/escalation"
+	"github.com/elemc/gotelegrambot/flood"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// CheckFlood counts message of user and applies flood actions of chat if user exceeded limit,
+// admins are not limited
+func (s *Server) CheckFlood(msg *tgbotapi.Message) {
+	if s.FloodLimiter == nil || msg.From == nil || msg.Chat.IsPrivate() {
+		return
+	}
+	settings, err := db.GetChatSettings(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in CheckFlood -> GetChatSettings: %s", err)
+		return
+	}
+	if !settings.Flood.Enabled {
+		return
+	}
+
+	messages, window := settings.Flood.GetLimit()
+	burst, exceeded := s.FloodLimiter.Hit(msg.Chat.ID, msg.From.ID, msg.MessageID, flood.Limit{Messages: messages, Window: window})
+	if !exceeded {
+		return
+	}
+	role, err := s.getUserRole(msg.From.ID, msg.Chat)
+	if err != nil {
+		log.Printf("Error in CheckFlood -> getUserRole: %s", err)
+		return
+	}
+	if role >= RoleAdmin {
+		return
+	}
+	log.Printf("[%s] flood in chat %d: %d messages", msg.From.String(), msg.Chat.ID, len(burst))
+
+	lang := getLanguage(msg.Chat, nil)
+	reason := i18n.T(lang, "flood.reason")
+	for _, action := range settings.Flood.GetActions() {
+		switch action {
+		case db.FloodActionDelete:
+			s.removeBurst(msg, burst, lang, reason)
+		case db.FloodActionWarn:
+			if err := s.warnUser(msg.Chat, msg.From, &s.Bot.Self, reason, msg, 0); err != nil {
+				log.Printf("Error in CheckFlood -> warnUser: %s", err)
+			}
+		case db.FloodActionMute:
+			until, err := s.restrict(msg.Chat.ID, msg.From, db.RestrictionMute, settings.Flood.GetMuteDuration(), nil, reason)
+			if err != nil {
+				log.Printf("Error in CheckFlood -> restrict: %s", err)
+				continue
+			}
+			s.SendMessage(formatRestriction(lang, db.RestrictionMute, msg.From, until, reason, db.GetChatLocation(msg.Chat.ID)), msg.Chat.ID, 0)
+		}
+	}
+}
+
+// removeBurst deletes messages of burst for reason, msg is the last message of burst
+func (s *Server) removeBurst(msg *tgbotapi.Message, burst []int, lang, reason string) {
+	removed := 0
+	for _, id := range burst {
+		source := msg
+		if id != msg.MessageID {
+			var err error
+			if source, err = db.GetMessage(msg.Chat.ID, id); err != nil {
+				log.Printf("Error in removeBurst -> GetMessage %d: %s", id, err)
+				continue
+			}
+		}
+		if err := s.removeMessage(source, reason, 0); err != nil {
+			log.Printf("Error in removeBurst -> removeMessage %d: %s", id, err)
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		s.SendMessage(i18n.T(lang, "flood.removed", msg.From.String(), removed)+i18n.T(lang, "reason", reason), msg.Chat.ID, 0)
+	}
+}
+
+// getFloodDescription returns human readable flood settings
+func getFloodDescription(lang string, settings db.FloodSettings) string {
+	state := i18n.T(lang, "flood.off")
+	if settings.Enabled {
+		state = i18n.T(lang, "flood.on")
+	}
+	messages, window := settings.GetLimit()
+	seconds := int(window / time.Second)
+	var actions []string
+	for _, action := range settings.GetActions() {
+		switch action {
+		case db.FloodActionWarn:
+			actions = append(actions, i18n.T(lang, "action.warn"))
+		case db.FloodActionMute:
+			actions = append(actions, i18n.T(lang, "action.mute", escalation.Duration(settings.GetMuteDuration())))
+		case db.FloodActionDelete:
+			actions = append(actions, i18n.T(lang, "action.delete_messages"))
+		}
+	}
+	return i18n.T(lang, "flood.description", state,
+		i18n.N(lang, "messages", messages, messages), i18n.N(lang, "seconds", seconds, seconds), strings.Join(actions, ", "))
+}
+
+// Flood command shows or changes flood protection settings of chat
+func (s *Server) Flood(msg *tgbotapi.Message) {
+	args := strings.Fields(strings.ToLower(msg.CommandArguments()))
+
+	settings, err := db.GetChatSettings(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in Flood -> GetChatSettings: %s", err)
+		return
+	}
+
+	lang := getMessageLanguage(msg)
+	if len(args) == 0 {
+		s.SendMessage(getFloodDescription(lang, settings.Flood), msg.Chat.ID, msg.MessageID)
+		return
+	}
+
+	if !s.checkRole(msg, RoleAdmin) {
+		return
+	}
+
+	switch {
+	case args[0] == "on" && len(args) == 1:
+		settings.Flood.Enabled = true
+	case args[0] == "off" && len(args) == 1:
+		settings.Flood.Enabled = false
+	case args[0] == "limit" && len(args) == 3:
+		messages, err := strconv.Atoi(args[1])
+		if err != nil || messages <= 0 {
+			s.SendError(i18n.T(lang, "flood.usage"), msg)
+			return
+		}
+		seconds, err := strconv.Atoi(args[2])
+		if err != nil || seconds <= 0 {
+			s.SendError(i18n.T(lang, "flood.usage"), msg)
+			return
+		}
+		settings.Flood.Messages, settings.Flood.Seconds = messages, seconds
+	case args[0] == "action" && len(args) > 1:
+		for _, action := range args[1:] {
+			switch action {
+			case db.FloodActionWarn, db.FloodActionMute, db.FloodActionDelete:
+			default:
+				s.SendError(i18n.T(lang, "flood.usage"), msg)
+				return
+			}
+		}
+		settings.Flood.Actions = args[1:]
+	case args[0] == "mute" && len(args) == 2:
+		d, err := parseDuration(args[1])
+		if err != nil {
+			s.SendError(i18n.ErrorText(lang, err), msg)
+			return
+		}
+		settings.Flood.MuteDuration = escalation.Duration(d)
+	default:
+		s.SendError(i18n.T(lang, "flood.usage"), msg)
+		return
+	}
+
+	if err = db.SaveChatSettings(settings); err != nil {
+		log.Printf("Error in Flood -> SaveChatSettings: %s", err)
+		return
+	}
+	s.SendMessage(getFloodDescription(lang, settings.Flood), msg.Chat.ID, msg.MessageID)
+}
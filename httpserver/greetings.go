@@ -0,0 +1,201 @@
+package httpserver
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+var markdownEscaper = strings.NewReplacer("_", "\\_", "*", "\\*", "[", "\\[", "`", "\\`")
+
+// escapeGreeting escapes text for greeting format
+func escapeGreeting(text, format string) string {
+	switch format {
+	case db.GreetingFormatHTML:
+		return html.EscapeString(text)
+	case db.GreetingFormatMarkdown:
+		return markdownEscaper.Replace(text)
+	}
+	return text
+}
+
+func getUserName(user *tgbotapi.User) string {
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if name == "" {
+		name = user.UserName
+	}
+	return name
+}
+
+// getMention returns link to user in greeting format
+func getMention(user *tgbotapi.User, format string) string {
+	name := escapeGreeting(getUserName(user), format)
+	switch format {
+	case db.GreetingFormatHTML:
+		return fmt.Sprintf(`<a href="tg://user?id=%d">%s</a>`, user.ID, name)
+	case db.GreetingFormatMarkdown:
+		return fmt.Sprintf("[%s](tg://user?id=%d)", name, user.ID)
+	}
+	if user.UserName != "" {
+		return "@" + user.UserName
+	}
+	return name
+}
+
+// formatGreeting returns greeting text with placeholders replaced for user
+func (s *Server) formatGreeting(g db.Greeting, chat *tgbotapi.Chat, user *tgbotapi.User) string {
+	count := "?"
+	if strings.Contains(g.Text, "{count}") {
+		if n, err := s.Bot.GetChatMembersCount(tgbotapi.ChatConfig{ChatID: chat.ID}); err != nil {
+			log.Printf("Error in formatGreeting -> GetChatMembersCount: %s", err)
+		} else {
+			count = strconv.Itoa(n)
+		}
+	}
+	return strings.NewReplacer(
+		"{name}", escapeGreeting(getUserName(user), g.Format),
+		"{mention}", getMention(user, g.Format),
+		"{chat}", escapeGreeting(getChatName(chat), g.Format),
+		"{count}", count,
+	).Replace(g.Text)
+}
+
+// sendGreeting sends greeting for user to chat, message is scheduled to delete if greeting has delete timeout
+func (s *Server) sendGreeting(g db.Greeting, chat *tgbotapi.Chat, user *tgbotapi.User, replyID int) (err error) {
+	if g.Text == "" {
+		return
+	}
+	msg := tgbotapi.NewMessage(chat.ID, s.formatGreeting(g, chat, user))
+	switch g.Format {
+	case db.GreetingFormatMarkdown:
+		msg.ParseMode = tgbotapi.ModeMarkdown
+	case db.GreetingFormatHTML:
+		msg.ParseMode = tgbotapi.ModeHTML
+	}
+	if replyID != 0 {
+		msg.ReplyToMessageID = replyID
+	}
+	sent, err := s.Bot.Send(msg)
+	if err != nil {
+		return
+	}
+	if g.DeleteAfter > 0 {
+		err = db.ScheduleDelete(chat.ID, sent.MessageID, time.Now().Add(time.Duration(g.DeleteAfter)*time.Minute))
+	}
+	return
+}
+
+// deleteExpiredMessages deletes bot messages scheduled to delete
+func (s *Server) deleteExpiredMessages() {
+	deletes, err := db.GetExpiredAutoDeletes(time.Now())
+	if err != nil {
+		log.Printf("Error in GetExpiredAutoDeletes: %s", err)
+		return
+	}
+	for _, d := range deletes {
+		if err = s.deleteMessage(d.ChatID, d.MessageID); err != nil {
+			// message may be already deleted by admins
+			log.Printf("Error in delete message %d in chat %d: %s", d.MessageID, d.ChatID, err)
+		}
+		if err = db.RemoveAutoDelete(d.ChatID, d.MessageID); err != nil {
+			log.Printf("Error in RemoveAutoDelete: %s", err)
+		}
+	}
+}
+
+// SetWelcome command shows or changes greeting for joined members
+func (s *Server) SetWelcome(msg *tgbotapi.Message) {
+	s.setGreeting(msg, true)
+}
+
+// SetGoodbye command shows or changes farewell for left members
+func (s *Server) SetGoodbye(msg *tgbotapi.Message) {
+	s.setGreeting(msg, false)
+}
+
+func (s *Server) setGreeting(msg *tgbotapi.Message, welcome bool) {
+	settings, err := db.GetChatSettings(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in setGreeting -> GetChatSettings: %s", err)
+		return
+	}
+	g := &settings.Goodbye
+	if welcome {
+		g = &settings.Welcome
+	}
+
+	lang := getMessageLanguage(msg)
+	usage := i18n.T(lang, "greeting.usage", msg.Command())
+	args := strings.TrimSpace(msg.CommandArguments())
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		s.SendError(describeGreeting(lang, *g)+"\n"+usage, msg)
+		return
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "off":
+		g.Text = ""
+	case "delete":
+		minutes := -1
+		if len(fields) == 2 {
+			if minutes, err = strconv.Atoi(fields[1]); err != nil {
+				minutes = -1
+			}
+		}
+		if minutes < 0 {
+			s.SendError(usage, msg)
+			return
+		}
+		g.DeleteAfter = minutes
+	case db.GreetingFormatMarkdown, db.GreetingFormatHTML:
+		text := strings.TrimSpace(args[len(fields[0]):])
+		if text == "" {
+			s.SendError(usage, msg)
+			return
+		}
+		g.Text, g.Format = text, strings.ToLower(fields[0])
+	default:
+		g.Text, g.Format = args, db.GreetingFormatPlain
+	}
+
+	if g.Text != "" {
+		// preview checks formatting of text
+		if err = s.sendGreeting(db.Greeting{Text: g.Text, Format: g.Format}, msg.Chat, msg.From, msg.MessageID); err != nil {
+			s.SendError(i18n.T(lang, "greeting.invalid", err), msg)
+			return
+		}
+	}
+	if err = db.SaveChatSettings(settings); err != nil {
+		log.Printf("Error in setGreeting -> SaveChatSettings: %s", err)
+		return
+	}
+	s.SendError(describeGreeting(lang, *g), msg)
+}
+
+// describeGreeting returns human readable greeting settings
+func describeGreeting(lang string, g db.Greeting) string {
+	if g.Text == "" {
+		return i18n.T(lang, "greeting.off")
+	}
+	format := i18n.T(lang, "greeting.plain")
+	switch g.Format {
+	case db.GreetingFormatMarkdown:
+		format = "Markdown"
+	case db.GreetingFormatHTML:
+		format = "HTML"
+	}
+	deleteAfter := i18n.T(lang, "greeting.kept")
+	if g.DeleteAfter > 0 {
+		deleteAfter = i18n.T(lang, "greeting.delete_after", g.DeleteAfter)
+	}
+	return i18n.T(lang, "greeting.description", format, deleteAfter, g.Text)
+}
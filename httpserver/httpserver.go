@@ -3,6 +3,7 @@ package httpserver
 import (
 	"fmt"
 	"html"
+	"io"
 	"log"
 	"net/http"
 	"regexp"
@@ -11,33 +12,43 @@ import (
 	"time"
 
 	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/flood"
+	"github.com/elemc/gotelegrambot/i18n"
 
 	"github.com/gin-gonic/gin"
 	"gopkg.in/telegram-bot-api.v4"
 )
 
-// Server is a main object
+// Server is a main object, it owns photo, file and censore caches and bot commands
+// and must not be copied after first use
 type Server struct {
 	Addr          string
+	BaseURL       string
 	Bot           *tgbotapi.BotAPI
 	PhotoCache    PhotosCache
 	FileCache     FilesCache
 	APIKey        string
-	CensList      []string
+	CensList      CensList
+	CensFilters   CensFilters
+	FloodLimiter  *flood.Limiter // nil means flood isn't checked
+	Commands      CommandRouter
+	Callbacks     CallbackRouter
 	StaticDirPath string
+	AdminToken    string // token of web archive admins, empty means archive has no admins
 }
 
 const (
+	// header is a format of page header with language, title and language switcher
 	header = `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN"
         "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd">
-	<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="en">
+	<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="%s">
     <head>
-		<title>Telegram logs</title>
+		<title>%s</title>
 		<meta charset="utf-8;" />
 		<style type="text/css">
 			TH {
-		    	background: #FFFFFF; /* Цвет фона */
-		    	color: white; /* Цвет текста */
+		    	background: #FFFFFF; /* background color */
+		    	color: white; /* text color */
 		   	}
 			TD {
 				vertical-align: top;
@@ -48,17 +59,56 @@ const (
 			P.reply {
 				color: grey;
 			}
+			TABLE.calendar TD.day {
+				width: 40px;
+				height: 40px;
+				text-align: center;
+				vertical-align: middle;
+			}
+			TABLE.calendar TH {
+				color: grey;
+			}
+			TD.today {
+				border: 1px solid grey;
+			}
+			TD.level1 {
+				background: #D6E6F5;
+			}
+			TD.level2 {
+				background: #A9CBEA;
+			}
+			TD.level3 {
+				background: #6FA6DA;
+			}
+			TD.level4 {
+				background: #3A7FC1;
+			}
+			TR.removed {
+				color: grey;
+			}
+			P.removed {
+				color: #C0392B;
+			}
+			P.lang {
+				float: right;
+			}
 		</style>
     </head>
     <body>
-	<h2><a href="/">Telegram logs</a></h2>`
+	%s
+	<h2><a href="/">%s</a></h2>`
 	footer = `</body>
 </html>`
 	tableBegin = `<table border="0"><caption>%s</caption>`
 	classEven  = `class="even"`
 	tableEnd   = `</table>`
+
+	messagesPageSize  = 500
+	messagesFlushSize = 50
 )
 
+var linkRegexp = regexp.MustCompile(`(http|ftp|https):\/\/([\w\-_]+(?:(?:\.[\w\-_]+)+))([\w\-\.,@?^=%&amp;:/~\+#]*[\w\-\@?^=%&amp;/~\+#])?`)
+
 // Start method starts http server
 func (s *Server) Start() {
 	s.UpdatePhotoCache()
@@ -67,22 +117,44 @@ func (s *Server) Start() {
 	r := gin.Default()
 
 	r.StaticFS("/static", http.Dir(s.StaticDirPath))
-	r.GET("/chat/:chat_id/:year/:month/:day", s.dayPage)
-	r.GET("/chat/:chat_id/:year/:month", s.monthPage)
-	r.GET("/chat/:chat_id/:year", s.yearPage)
-	r.GET("/chat/:chat_id/", s.chatPage)
 
+	chat := r.Group("/chat/:chat_id", s.checkChatVisible)
+	chat.GET("/feed.atom", s.atomFeedPage)
+	chat.GET("/feed.rss", s.rssFeedPage)
+	chat.GET("/calendar/:year/:month", s.calendarPage)
+	chat.GET("/calendar", s.calendarPage)
+	chat.GET("/live", s.livePage)
+	chat.GET("/:year/:month/:day", s.dayPage)
+	chat.GET("/:year/:month", s.monthPage)
+	chat.GET("/:year", s.yearPage)
+	chat.GET("/", s.chatPage)
+
+	r.GET("/admin", s.adminPage)
+	r.POST("/admin", s.adminLogin)
 	r.GET("/", s.mainPage)
 
 	r.Run(s.Addr)
 }
 
 func (s *Server) mainPage(c *gin.Context) {
-	page := parseTemplate(s.getMain())
+	lang := getWebLanguage(c)
+	page := parseTemplate(c, lang, s.getMain(lang))
 	c.Header("X-XSS-Protection", "1; mode=block")
 	c.Data(http.StatusOK, "text/html", page)
 }
 
+// checkChatVisible aborts request to chat hidden from web archive
+func (s *Server) checkChatVisible(c *gin.Context) {
+	chatID, err := strconv.ParseInt(c.Param("chat_id"), 10, 64)
+	if err != nil {
+		return
+	}
+	if db.ChatIsHidden(chatID) {
+		c.String(http.StatusNotFound, i18n.T(getWebLanguage(c), "web.chat_not_found"))
+		c.Abort()
+	}
+}
+
 func (s *Server) chatPage(c *gin.Context) {
 	strChatID := c.Param("chat_id")
 	chatID, err := strconv.ParseInt(strChatID, 10, 64)
@@ -91,7 +163,8 @@ func (s *Server) chatPage(c *gin.Context) {
 		return
 	}
 
-	page := parseTemplate(s.getYears(chatID))
+	lang := getWebLanguage(c)
+	page := parseTemplate(c, lang, s.getYears(lang, chatID, s.getLocation(c, chatID)))
 	// page := parseTemplate(s.getMessages(chatID))
 	c.Header("X-XSS-Protection", "1; mode=block")
 	c.Data(http.StatusOK, "text/html", page)
@@ -111,7 +184,8 @@ func (s *Server) yearPage(c *gin.Context) {
 		return
 	}
 
-	page := parseTemplate(s.getMonths(chatID, year))
+	lang := getWebLanguage(c)
+	page := parseTemplate(c, lang, s.getMonths(lang, chatID, year, s.getLocation(c, chatID)))
 	// page := parseTemplate(s.getMessages(chatID))
 	c.Header("X-XSS-Protection", "1; mode=block")
 	c.Data(http.StatusOK, "text/html", page)
@@ -137,7 +211,8 @@ func (s *Server) monthPage(c *gin.Context) {
 		return
 	}
 
-	page := parseTemplate(s.getDates(chatID, year, month))
+	lang := getWebLanguage(c)
+	page := parseTemplate(c, lang, s.getDates(lang, chatID, year, month, s.getLocation(c, chatID)))
 	c.Header("X-XSS-Protection", "1; mode=block")
 	c.Data(http.StatusOK, "text/html", page)
 }
@@ -168,12 +243,23 @@ func (s *Server) dayPage(c *gin.Context) {
 		return
 	}
 
-	beginTime := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
-	endTime := time.Date(year, time.Month(month), day, 23, 59, 59, 100, time.Local)
+	after, err := db.ParseMessageCursor(c.Query("after"))
+	if err != nil {
+		c.String(http.StatusOK, err.Error())
+		return
+	}
+
+	lang := getWebLanguage(c)
+	loc := s.getLocation(c, chatID)
+	beginTime := time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+	endTime := beginTime.AddDate(0, 0, 1).Add(-time.Second)
 
-	page := parseTemplate(s.getMessages(chatID, beginTime, endTime))
 	c.Header("X-XSS-Protection", "1; mode=block")
-	c.Data(http.StatusOK, "text/html", page)
+	c.Header("Content-Type", "text/html")
+	c.Status(http.StatusOK)
+	io.WriteString(c.Writer, getHeader(c, lang)+"\n")
+	s.writeMessages(c.Writer, lang, chatID, beginTime, endTime, after, loc, s.isWebAdmin(c))
+	io.WriteString(c.Writer, "\n"+footer)
 }
 
 func (s *Server) updatePhotoCacheServer() {
@@ -186,13 +272,19 @@ func (s *Server) updatePhotoCacheServer() {
 	}
 }
 
-func parseTemplate(body string) []byte {
-	result := fmt.Sprintf("%s\n%s\n%s", header, body, footer)
+// getHeader returns page header in language
+func getHeader(c *gin.Context, lang string) string {
+	title := i18n.T(lang, "web.title")
+	return fmt.Sprintf(header, lang, title, getLanguageSwitcher(c, lang), title)
+}
+
+func parseTemplate(c *gin.Context, lang, body string) []byte {
+	result := fmt.Sprintf("%s\n%s\n%s", getHeader(c, lang), body, footer)
 	return []byte(result)
 }
 
-func (s *Server) getMain() (body string) {
-	body += fmt.Sprintf(tableBegin, "Chats")
+func (s *Server) getMain(lang string) (body string) {
+	body += fmt.Sprintf(tableBegin, i18n.T(lang, "web.chats"))
 
 	chats, err := db.GetChats()
 	if err != nil {
@@ -200,29 +292,22 @@ func (s *Server) getMain() (body string) {
 		return ""
 	}
 
-	for index, chat := range chats {
-		chatName := chat.Title
-		if chat.Title == "" {
-			chatName = chat.UserName
-		}
-
-		if chatName == "" {
-			chatName = strings.TrimSpace(fmt.Sprintf("%s %s", chat.FirstName, chat.LastName))
-		}
-		if chatName != "" && (chat.FirstName != "" || chat.LastName != "") {
-			names := strings.TrimSpace(chat.FirstName + " " + chat.LastName)
-			chatName += fmt.Sprintf(" (%s)", names)
+	index := 0
+	for _, chat := range chats {
+		if db.ChatIsHidden(chat.ID) {
+			continue
 		}
 
 		class := ""
 		if index%2 == 0 {
 			class = classEven
 		}
+		index++
 
 		body += fmt.Sprintf(`
 			<tr %s>
 				<td class="la"><a href="/chat/%d/">%s</a></td>
-			</tr>`, class, chat.ID, chatName)
+			</tr>`, class, chat.ID, formatMessage(getChatName(chat)))
 
 	}
 	body += tableEnd
@@ -230,92 +315,158 @@ func (s *Server) getMain() (body string) {
 	return
 }
 
+func getChatName(chat *tgbotapi.Chat) string {
+	chatName := chat.Title
+	if chat.Title == "" {
+		chatName = chat.UserName
+	}
+
+	if chatName == "" {
+		chatName = strings.TrimSpace(fmt.Sprintf("%s %s", chat.FirstName, chat.LastName))
+	}
+	if chatName != "" && (chat.FirstName != "" || chat.LastName != "") {
+		names := strings.TrimSpace(chat.FirstName + " " + chat.LastName)
+		chatName += fmt.Sprintf(" (%s)", names)
+	}
+	return chatName
+}
+
 func getDate(id int64) (body string) {
 	// TODO: create it
 	return
 }
 
-func (s *Server) getMessages(chatID int64, beginTime, endTime time.Time) (body string) {
-	body += fmt.Sprintf(tableBegin, "Messages")
+// writeMessages writes page of messages to w, rows are flushed to client while they are read from database,
+// messages removed by moderation are written for admins only
+func (s *Server) writeMessages(w io.Writer, lang string, chatID int64, beginTime, endTime time.Time, after db.MessageCursor, loc *time.Location, admin bool) {
+	io.WriteString(w, getTimezoneInfo(lang, loc))
+	if !after.IsZero() {
+		fmt.Fprintf(w, `<p><a href="?">%s</a></p>`, i18n.T(lang, "web.first_page"))
+	}
+	fmt.Fprintf(w, tableBegin, i18n.T(lang, "web.messages"))
 
-	msgs, err := db.GetMessagesByDate(chatID, beginTime, endTime)
+	marks, err := db.GetModeratedMessages(chatID, beginTime, endTime)
 	if err != nil {
-		log.Printf("Error in getMessages: %s", err)
-		return ""
+		log.Printf("Error in writeMessages -> GetModeratedMessages: %s", err)
+		io.WriteString(w, tableEnd)
+		return
 	}
 
-	for index, msg := range msgs {
-		t := time.Unix(int64(msg.Date), 0)
-		name := msg.From.UserName
-		if msg.From.UserName == "" {
-			name = fmt.Sprintf("%s %s", msg.From.FirstName, msg.From.LastName)
+	it, err := db.IterateMessages(chatID, beginTime, endTime, after, messagesPageSize+1)
+	if err != nil {
+		log.Printf("Error in writeMessages: %s", err)
+		io.WriteString(w, tableEnd)
+		return
+	}
+
+	var last, next *db.MessageCursor
+	index, rows := 0, 0
+	for it.Next() {
+		if index == messagesPageSize {
+			// the next page begins after the last written message
+			next = last
+			break
+		}
+		msg := it.Message()
+		mark := marks[msg.MessageID]
+		if mark == nil || admin {
+			io.WriteString(w, s.formatMessageRow(lang, msg, rows, loc, mark, admin))
+			rows++
 		}
-		if msg.From.FirstName != "" || msg.From.LastName != "" {
-			names := strings.TrimSpace(msg.From.FirstName + " " + msg.From.LastName)
-			name += fmt.Sprintf(" (%s)", names)
+		cursor := it.Cursor()
+		last = &cursor
+		index++
+		if f, ok := w.(http.Flusher); ok && index%messagesFlushSize == 0 {
+			f.Flush()
 		}
+	}
+	if err = it.Close(); err != nil {
+		log.Printf("Error in writeMessages: %s", err)
+	}
+	io.WriteString(w, tableEnd)
 
-		msgText := formatMessage(msg.Text)
-		re := regexp.MustCompile(`(http|ftp|https):\/\/([\w\-_]+(?:(?:\.[\w\-_]+)+))([\w\-\.,@?^=%&amp;:/~\+#]*[\w\-\@?^=%&amp;/~\+#])?`)
-		msgText = re.ReplaceAllString(msgText, `<a href="$0">$0</a>`)
+	if next != nil {
+		fmt.Fprintf(w, `<p><a href="?after=%s">%s</a></p>`, next, i18n.T(lang, "web.next_page"))
+	} else if now := time.Now(); !now.Before(beginTime) && !now.After(endTime) {
+		// the last page of today receives new messages
+		io.WriteString(w, getLiveScript(chatID, beginTime, endTime))
+	}
+}
 
-		if msg.ReplyToMessage != nil {
-			lt := time.Unix(int64(msg.ReplyToMessage.Date), 0)
-			replyLink := fmt.Sprintf("/chat/%d/%d/%d/%d#%s", msg.Chat.ID, lt.Year(), lt.Month(), lt.Day(), lt.Format("15:04:05"))
-			msgText = fmt.Sprintf(`<p class="reply"> <a href="%s">></a> %s</p><p>%s</p>`, replyLink, msg.ReplyToMessage.Text, msgText)
-		}
+// formatMessageRow returns table row of message in language, mark is not nil for message removed by moderation,
+// admin is true if viewer is archive admin
+func (s *Server) formatMessageRow(lang string, msg *tgbotapi.Message, index int, loc *time.Location, mark *db.ModeratedMessage, admin bool) string {
+	t := time.Unix(int64(msg.Date), 0).In(loc)
+	name := msg.From.UserName
+	if msg.From.UserName == "" {
+		name = fmt.Sprintf("%s %s", msg.From.FirstName, msg.From.LastName)
+	}
+	if msg.From.FirstName != "" || msg.From.LastName != "" {
+		names := strings.TrimSpace(msg.From.FirstName + " " + msg.From.LastName)
+		name += fmt.Sprintf(" (%s)", names)
+	}
 
-		class := ""
-		if index%2 == 0 {
-			class = classEven
-		}
+	msgText := formatMessageText(msg.Text)
 
-		photo := s.GetPhotoFileName(int64(msg.From.ID))
-		timeStr := t.Format("15:04:05")
+	if msg.ReplyToMessage != nil {
+		lt := time.Unix(int64(msg.ReplyToMessage.Date), 0).In(loc)
+		replyLink := fmt.Sprintf("/chat/%d/%d/%d/%d#%s", msg.Chat.ID, lt.Year(), lt.Month(), lt.Day(), lt.Format("15:04:05"))
+		msgText = fmt.Sprintf(`<p class="reply"> <a href="%s">></a> %s</p><p>%s</p>`, replyLink, getReplyText(lang, msg.Chat.ID, msg.ReplyToMessage, admin), msgText)
+	}
 
-		if msg.Audio != nil {
-			msgText += fmt.Sprintf(`<p><a href="/%s">Audio in message</a></p>`, s.GetFileNameByFileID(msg.Chat.ID, msg.Audio.FileID))
-		}
-		if msg.Document != nil {
-			msgText += fmt.Sprintf(`<p><a href="/%s">Document in message</a></p>`, s.GetFileNameByFileID(msg.Chat.ID, msg.Document.FileID))
-		}
-		if msg.Photo != nil {
-			msgText += "<p>"
-			f := (*msg.Photo)[len(*msg.Photo)-1]
-			//for _, f := range *msg.Photo {
-			photoName := s.GetFileNameByFileIDURL(msg.Chat.ID, f.FileID)
-			msgText += fmt.Sprintf(`<p><a href="/%s"><img src="/%s"></img></a>`, photoName, photoName)
-			//}
-			msgText += "</p>"
-		}
-		if msg.Sticker != nil {
-			msgText += fmt.Sprintf(`<p><img src="/%s"></img></p>`, s.GetFileNameByFileIDURL(msg.Chat.ID, msg.Sticker.FileID))
-		}
-		if msg.Video != nil {
-			msgText += fmt.Sprintf(`<p><a href="/%s">Video in message</a></p>`, s.GetFileNameByFileIDURL(msg.Chat.ID, msg.Video.FileID))
-		}
-		if msg.Voice != nil {
-			msgText += fmt.Sprintf(`<p><a href="/%s">Voice in message</a></p>`, s.GetFileNameByFileIDURL(msg.Chat.ID, msg.Voice.FileID))
-		}
+	class := ""
+	if index%2 == 0 {
+		class = classEven
+	}
+	if mark != nil {
+		class = `class="removed"`
+		msgText = fmt.Sprintf(`<p class="removed">%s</p>%s`, i18n.T(lang, "web.removed", formatMessage(mark.Reason)), msgText)
+	}
 
-		body += fmt.Sprintf(`
-			<tr %s>
-				<td class="la" align="center" width='3%%'><img src="/%s" height="30px" width="30px"></img></td>
-				<td class="la" align="center" width='5%%'><a id="%s" name="%s" href="#%s" class="time">%s</td>
-				<td class="la" width='17%%'><strong>%s</strong></td>
-				<td class="la">%s</td>
-				<td style="display:none;">%d</td>
-			</tr>`, class, photo, timeStr, timeStr, timeStr, timeStr, formatMessage(name), msgText, msg.MessageID)
+	photo := s.GetPhotoFileName(int64(msg.From.ID))
+	timeStr := t.Format("15:04:05")
+
+	if msg.Audio != nil {
+		msgText += fmt.Sprintf(`<p><a href="/%s">%s</a></p>`, s.GetFileNameByFileID(msg.Chat.ID, msg.Audio.FileID), i18n.T(lang, "web.audio"))
+	}
+	if msg.Document != nil {
+		msgText += fmt.Sprintf(`<p><a href="/%s">%s</a></p>`, s.GetFileNameByFileID(msg.Chat.ID, msg.Document.FileID), i18n.T(lang, "web.document"))
+	}
+	if msg.Photo != nil {
+		msgText += "<p>"
+		f := (*msg.Photo)[len(*msg.Photo)-1]
+		//for _, f := range *msg.Photo {
+		photoName := s.GetFileNameByFileIDURL(msg.Chat.ID, f.FileID)
+		msgText += fmt.Sprintf(`<p><a href="/%s"><img src="/%s"></img></a>`, photoName, photoName)
+		//}
+		msgText += "</p>"
+	}
+	if msg.Sticker != nil {
+		msgText += fmt.Sprintf(`<p><img src="/%s"></img></p>`, s.GetFileNameByFileIDURL(msg.Chat.ID, msg.Sticker.FileID))
+	}
+	if msg.Video != nil {
+		msgText += fmt.Sprintf(`<p><a href="/%s">%s</a></p>`, s.GetFileNameByFileIDURL(msg.Chat.ID, msg.Video.FileID), i18n.T(lang, "web.video"))
+	}
+	if msg.Voice != nil {
+		msgText += fmt.Sprintf(`<p><a href="/%s">%s</a></p>`, s.GetFileNameByFileIDURL(msg.Chat.ID, msg.Voice.FileID), i18n.T(lang, "web.voice"))
 	}
-	body += tableEnd
 
-	return
+	return fmt.Sprintf(`
+		<tr %s id="msg%d">
+			<td class="la" align="center" width='3%%'><img src="/%s" height="30px" width="30px"></img></td>
+			<td class="la" align="center" width='5%%'><a id="%s" name="%s" href="#%s" class="time">%s</td>
+			<td class="la" width='17%%'><strong>%s</strong></td>
+			<td class="la">%s</td>
+			<td style="display:none;">%d</td>
+		</tr>`, class, msg.MessageID, photo, timeStr, timeStr, timeStr, timeStr, formatMessage(name), msgText, msg.MessageID)
 }
 
-func (s *Server) getYears(chatID int64) (body string) {
-	body += fmt.Sprintf(tableBegin, "Years")
+func (s *Server) getYears(lang string, chatID int64, loc *time.Location) (body string) {
+	body += fmt.Sprintf(`<p><a href="/chat/%d/calendar">%s</a> | <a href="/chat/%d/feed.atom">Atom</a> | <a href="/chat/%d/feed.rss">RSS</a></p>`,
+		chatID, i18n.T(lang, "web.calendar"), chatID, chatID)
+	body += fmt.Sprintf(tableBegin, i18n.T(lang, "web.years"))
 
-	dates, err := db.GetYears(chatID)
+	dates, err := db.GetYears(chatID, loc)
 	if err != nil {
 		log.Printf("Error in GetYears for chat %d: %s", chatID, err)
 		return ""
@@ -336,10 +487,10 @@ func (s *Server) getYears(chatID int64) (body string) {
 	return
 }
 
-func (s *Server) getMonths(chatID int64, year int) (body string) {
-	body += fmt.Sprintf(tableBegin, "Months")
+func (s *Server) getMonths(lang string, chatID int64, year int, loc *time.Location) (body string) {
+	body += fmt.Sprintf(tableBegin, i18n.T(lang, "web.months"))
 
-	dates, err := db.GetMonthList(chatID, year)
+	dates, err := db.GetMonthList(chatID, year, loc)
 	if err != nil {
 		log.Printf("Error in GetYears for chat %d: %s", chatID, err)
 		return ""
@@ -352,7 +503,7 @@ func (s *Server) getMonths(chatID int64, year int) (body string) {
 		body += fmt.Sprintf(`
 			<tr %s>
 				<td class="la" ><a href="/chat/%d/%d/%d">%s</a></td>
-			</tr>`, class, chatID, year, date, date.String())
+			</tr>`, class, chatID, year, date, i18n.MonthName(lang, date))
 
 	}
 	body += tableEnd
@@ -360,14 +511,20 @@ func (s *Server) getMonths(chatID int64, year int) (body string) {
 	return
 }
 
-func (s *Server) getDates(chatID int64, year int, month int) (body string) {
-	body += fmt.Sprintf(tableBegin, "Dates")
+func (s *Server) getDates(lang string, chatID int64, year int, month int, loc *time.Location) (body string) {
+	body += fmt.Sprintf(`<p><a href="%s">%s</a></p>`, getCalendarLink(chatID, time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc)), i18n.T(lang, "web.calendar"))
+	body += fmt.Sprintf(tableBegin, formatMonth(lang, time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc)))
 
-	dates, err := db.GetDates(chatID, year, month)
+	dates, err := db.GetDates(chatID, year, month, loc)
 	if err != nil {
 		log.Printf("Error in GetYears for chat %d: %s", chatID, err)
 		return ""
 	}
+	counts, err := db.GetDayCounts(chatID, year, month, loc)
+	if err != nil {
+		log.Printf("Error in GetDayCounts for chat %d: %s", chatID, err)
+		return ""
+	}
 	for index, date := range dates {
 		class := ""
 		if index%2 == 0 {
@@ -376,7 +533,8 @@ func (s *Server) getDates(chatID int64, year int, month int) (body string) {
 		body += fmt.Sprintf(`
 			<tr %s>
 				<td class="la" ><a href="/chat/%d/%d/%d/%d">%02d</a></td>
-			</tr>`, class, chatID, year, month, date, date)
+				<td class="la">%d</td>
+			</tr>`, class, chatID, year, month, date, date, counts[date])
 
 	}
 	body += tableEnd
@@ -397,6 +555,26 @@ func searchAndReplace(msg string, phrase string, replace string) string {
 	return string(bytesMsg)
 }
 
+// formatMessageText returns escaped text of message with links
+func formatMessageText(text string) string {
+	return linkRegexp.ReplaceAllString(formatMessage(text), `<a href="$0">$0</a>`)
+}
+
+// getReplyText returns quote of replied message, text of message removed by moderation is shown to admins only
+func getReplyText(lang string, chatID int64, reply *tgbotapi.Message, admin bool) string {
+	if !admin {
+		mark, err := db.GetModeratedMessage(chatID, reply.MessageID)
+		if err != nil {
+			log.Printf("Error in getReplyText -> GetModeratedMessage: %s", err)
+			return i18n.T(lang, "web.reply_hidden")
+		}
+		if mark != nil {
+			return i18n.T(lang, "web.reply_hidden")
+		}
+	}
+	return formatMessageText(reply.Text)
+}
+
 func formatMessage(msg string) string {
 	return html.EscapeString(msg)
 	// msg = searchAndReplace(msg, "<script", "%SCRIPT")
@@ -0,0 +1,119 @@
+package httpserver
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+const (
+	langParam  = "lang"
+	langCookie = "lang"
+	langMaxAge = 365 * 24 * 60 * 60
+)
+
+// getLanguage returns language of bot replies to user in chat: language of chat settings,
+// language of user in private chat without language setting or default language
+func getLanguage(chat *tgbotapi.Chat, user *tgbotapi.User) string {
+	settings, err := db.GetChatSettings(chat.ID)
+	if err != nil {
+		log.Printf("Error in getLanguage -> GetChatSettings: %s", err)
+	} else if settings.Language != "" {
+		return settings.Language
+	}
+	if chat.IsPrivate() && user != nil {
+		if lang, ok := i18n.Match(user.LanguageCode); ok {
+			return lang
+		}
+	}
+	return i18n.DefaultLanguage
+}
+
+// getMessageLanguage returns language of bot replies to message
+func getMessageLanguage(msg *tgbotapi.Message) string {
+	return getLanguage(msg.Chat, msg.From)
+}
+
+// getChatLanguage returns language of bot messages to chat without user
+func getChatLanguage(chatID int64) string {
+	return getLanguage(&tgbotapi.Chat{ID: chatID}, nil)
+}
+
+// Lang command shows or sets language of bot replies in chat
+func (s *Server) Lang(msg *tgbotapi.Message) {
+	name := strings.ToLower(strings.TrimSpace(msg.CommandArguments()))
+	if name == "" {
+		lang := getMessageLanguage(msg)
+		s.SendMessage(i18n.T(lang, "lang.current", lang, strings.Join(i18n.Languages(), ", ")), msg.Chat.ID, msg.MessageID)
+		return
+	}
+
+	if !s.checkRole(msg, RoleAdmin) {
+		return
+	}
+
+	if name == "default" {
+		name = ""
+	} else if _, ok := i18n.Match(name); !ok || len(name) != 2 {
+		lang := getMessageLanguage(msg)
+		s.SendError(i18n.T(lang, "lang.unknown", name, strings.Join(i18n.Languages(), ", ")), msg)
+		return
+	}
+
+	settings, err := db.GetChatSettings(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in Lang -> GetChatSettings: %s", err)
+		return
+	}
+	settings.Language = name
+	if err = db.SaveChatSettings(settings); err != nil {
+		log.Printf("Error in Lang -> SaveChatSettings: %s", err)
+		return
+	}
+	lang := getMessageLanguage(msg)
+	s.SendMessage(i18n.T(lang, "lang.set", lang), msg.Chat.ID, msg.MessageID)
+}
+
+// getWebLanguage returns language of web viewer, it is taken from query param lang
+// (and stored to cookie) or cookie lang, otherwise Accept-Language header used
+func getWebLanguage(c *gin.Context) string {
+	if name, ok := c.GetQuery(langParam); ok {
+		if lang, ok := i18n.Match(name); ok {
+			c.SetCookie(langCookie, lang, langMaxAge, "/", "", false, true)
+			return lang
+		}
+		// unknown or empty language resets viewer language
+		c.SetCookie(langCookie, "", -1, "/", "", false, true)
+	} else if name, err := c.Cookie(langCookie); err == nil {
+		if lang, ok := i18n.Match(name); ok {
+			return lang
+		}
+	}
+	c.Header("Vary", "Accept-Language")
+	if lang, ok := i18n.MatchAcceptLanguage(c.GetHeader("Accept-Language")); ok {
+		return lang
+	}
+	return i18n.DefaultLanguage
+}
+
+// getLanguageSwitcher returns links to current page in other languages, other query params like page or timezone are kept
+func getLanguageSwitcher(c *gin.Context, lang string) string {
+	var links []string
+	for _, l := range i18n.Languages() {
+		if l == lang {
+			links = append(links, strings.ToUpper(l))
+			continue
+		}
+		query := c.Request.URL.Query()
+		query.Set(langParam, l)
+		links = append(links, fmt.Sprintf(`<a href="?%s">%s</a>`, html.EscapeString(query.Encode()), strings.ToUpper(l)))
+	}
+	return fmt.Sprintf(`<p class="lang">%s</p>`, strings.Join(links, " | "))
+}
@@ -0,0 +1,126 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/elemc/gotelegrambot/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+const liveKeepAlive = 30 * time.Second
+
+// liveScript appends new messages, replaces edited messages and removes messages removed by moderation
+// in table of day page
+const liveScript = `
+	<script type="text/javascript">
+		(function() {
+			if (!window.EventSource) {
+				return;
+			}
+			var tables = document.getElementsByTagName("table");
+			var table = tables[tables.length - 1];
+			var source = new EventSource("/chat/%d/live?from=%d&to=%d");
+			var update = function(e, append) {
+				var data = JSON.parse(e.data);
+				var old = document.getElementById("msg" + data.id);
+				if (!data.html) {
+					if (old) {
+						old.parentNode.removeChild(old);
+					}
+					return;
+				}
+				var tbody = document.createElement("tbody");
+				tbody.innerHTML = data.html;
+				var row = tbody.getElementsByTagName("tr")[0];
+				if (old) {
+					old.parentNode.replaceChild(row, old);
+				} else if (append) {
+					table.appendChild(row);
+				}
+			};
+			source.addEventListener("new", function(e) { update(e, true); });
+			source.addEventListener("edit", function(e) { update(e, false); });
+			source.addEventListener("remove", function(e) { update(e, false); });
+		})();
+	</script>`
+
+type liveMessage struct {
+	ID   int    `json:"id"`
+	HTML string `json:"html"` // empty for message removed by moderation if viewer isn't admin
+}
+
+// livePage sends new, edited and removed by moderation messages of chat as server-sent events,
+// only messages posted between unix times from and to of viewed day are sent
+func (s *Server) livePage(c *gin.Context) {
+	strChatID := c.Param("chat_id")
+	chatID, err := strconv.ParseInt(strChatID, 10, 64)
+	if err != nil {
+		c.String(http.StatusOK, err.Error())
+		return
+	}
+	from, err := strconv.ParseInt(c.Query("from"), 10, 64)
+	if err != nil {
+		c.String(http.StatusOK, err.Error())
+		return
+	}
+	to, err := strconv.ParseInt(c.Query("to"), 10, 64)
+	if err != nil {
+		c.String(http.StatusOK, err.Error())
+		return
+	}
+	lang := getWebLanguage(c)
+	loc := s.getLocation(c, chatID)
+	admin := s.isWebAdmin(c)
+
+	events, cancel := db.Events.Subscribe(chatID)
+	defer cancel()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			if date := int64(event.Message.Date); date < from || date > to {
+				return true
+			}
+			var mark *db.ModeratedMessage
+			if event.Type != db.EventNewMessage {
+				if mark, err = db.GetModeratedMessage(chatID, event.Message.MessageID); err != nil {
+					log.Printf("Error in live -> GetModeratedMessage: %s", err)
+					return true
+				}
+			}
+			live := liveMessage{ID: event.Message.MessageID}
+			if mark == nil || admin {
+				live.HTML = s.formatMessageRow(lang, event.Message, 1, loc, mark, admin)
+			} else if event.Type != db.EventRemovedMessage {
+				// edit of removed message
+				return true
+			}
+			data, err := json.Marshal(live)
+			if err != nil {
+				log.Printf("Error in marshal live message: %s", err)
+				return true
+			}
+			c.SSEvent(event.Type, string(data))
+		case <-time.After(liveKeepAlive):
+			c.SSEvent("ping", "")
+		case <-c.Request.Context().Done():
+			return false
+		}
+		return true
+	})
+}
+
+func getLiveScript(chatID int64, beginTime, endTime time.Time) string {
+	return fmt.Sprintf(liveScript, chatID, beginTime.Unix(), endTime.Unix())
+}
@@ -0,0 +1,131 @@
+package httpserver
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/escalation"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// Counters with escalation policy
+const (
+	counterWarn = "warn"
+	counterCens = "cens"
+)
+
+// escalate applies step of policy for counter value of user, ok is false if no step is applied
+func (s *Server) escalate(chat *tgbotapi.Chat, user *tgbotapi.User, count int, policy escalation.Policy, reason string) (step escalation.Step, until time.Time, ok bool, err error) {
+	step, ok = policy.Decide(count)
+	if !ok {
+		return
+	}
+	switch step.Action {
+	case escalation.ActionMute:
+		until, err = s.restrict(chat.ID, user, db.RestrictionMute, time.Duration(step.Duration), nil, reason)
+	case escalation.ActionBan:
+		until, err = s.restrict(chat.ID, user, db.RestrictionBan, time.Duration(step.Duration), nil, reason)
+	}
+	return
+}
+
+// formatStep returns message about applied step of policy
+func formatStep(lang string, step escalation.Step, user *tgbotapi.User, until time.Time, reason string, loc *time.Location) string {
+	switch step.Action {
+	case escalation.ActionMute:
+		return formatRestriction(lang, db.RestrictionMute, user, until, reason, loc)
+	case escalation.ActionBan:
+		return formatRestriction(lang, db.RestrictionBan, user, until, reason, loc)
+	}
+	return i18n.T(lang, "policy.warning", user.String())
+}
+
+// getPolicyDescription returns human readable description of policy
+func getPolicyDescription(lang string, policy escalation.Policy) string {
+	if len(policy.Steps) == 0 {
+		return i18n.T(lang, "policy.empty")
+	}
+	var steps []string
+	for _, step := range policy.Steps {
+		var action string
+		switch step.Action {
+		case escalation.ActionWarn:
+			action = i18n.T(lang, "action.warn")
+		case escalation.ActionMute:
+			action = i18n.T(lang, "action.mute", step.Duration)
+		case escalation.ActionBan:
+			action = i18n.T(lang, "action.ban")
+			if step.Duration != 0 {
+				action = i18n.T(lang, "action.tempban", step.Duration)
+			}
+		}
+		steps = append(steps, fmt.Sprintf("%d → %s", step.Level, action))
+	}
+	return strings.Join(steps, ", ")
+}
+
+// Policy command shows or changes escalation policies of chat
+func (s *Server) Policy(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+
+	settings, err := db.GetChatSettings(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in Policy -> GetChatSettings: %s", err)
+		return
+	}
+
+	lang := getMessageLanguage(msg)
+	if len(args) == 0 {
+		s.SendMessage(i18n.T(lang, "policy.description",
+			getPolicyDescription(lang, settings.GetWarnPolicy()), getPolicyDescription(lang, settings.GetCensPolicy())), msg.Chat.ID, msg.MessageID)
+		return
+	}
+
+	counter := args[0]
+	if counter != counterWarn && counter != counterCens {
+		s.SendError(i18n.T(lang, "policy.usage"), msg)
+		return
+	}
+
+	if len(args) > 1 {
+		if !s.checkRole(msg, RoleAdmin) {
+			return
+		}
+
+		var policy *escalation.Policy
+		switch {
+		case args[1] == "reset" && len(args) == 2:
+		case args[1] == "set" && len(args) > 2:
+			p, err := escalation.ParsePolicy(args[2:])
+			if err != nil {
+				s.SendError(i18n.T(lang, "policy.invalid", err, i18n.T(lang, "policy.usage")), msg)
+				return
+			}
+			policy = &p
+		default:
+			s.SendError(i18n.T(lang, "policy.usage"), msg)
+			return
+		}
+
+		if counter == counterWarn {
+			settings.WarnPolicy = policy
+		} else {
+			settings.CensPolicy = policy
+		}
+		if err = db.SaveChatSettings(settings); err != nil {
+			log.Printf("Error in Policy -> SaveChatSettings: %s", err)
+			return
+		}
+	}
+
+	policy := settings.GetCensPolicy()
+	if counter == counterWarn {
+		policy = settings.GetWarnPolicy()
+	}
+	s.SendMessage(i18n.T(lang, "policy.counter", counter, getPolicyDescription(lang, policy), policy), msg.Chat.ID, msg.MessageID)
+}
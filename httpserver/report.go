@@ -0,0 +1,364 @@
+package httpserver
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+const (
+	reportPrefix     = "report"
+	reportTextLength = 200
+)
+
+// reportActions are actions on report in order of keyboard buttons
+var reportActions = []string{db.ReportWarn, db.ReportMute, db.ReportBan, db.ReportDismiss}
+
+// getPermalink returns link to message for chat admins: link in Telegram for public chats and supergroups,
+// link in web archive otherwise
+func (s *Server) getPermalink(msg *tgbotapi.Message) string {
+	if msg.Chat.UserName == "" && msg.Chat.IsSuperGroup() {
+		if id := strconv.FormatInt(msg.Chat.ID, 10); strings.HasPrefix(id, "-100") {
+			return fmt.Sprintf("https://t.me/c/%s/%d", id[4:], msg.MessageID)
+		}
+	}
+	return s.getSourceLink(msg)
+}
+
+// formatReport returns message with report for admins, handled report has a line about admin and action
+func formatReport(lang string, r *db.Report) string {
+	lines := []string{
+		i18n.T(lang, "report.title", r.Chat),
+		i18n.T(lang, "report.reporter", r.Reporter),
+		i18n.T(lang, "report.user", r.User),
+	}
+	if r.Reason != "" {
+		lines = append(lines, i18n.T(lang, "report.reason_line", r.Reason))
+	}
+	if r.Text != "" {
+		lines = append(lines, i18n.T(lang, "report.text", r.Text))
+	}
+	if r.Link != "" {
+		lines = append(lines, r.Link)
+	}
+	if r.Status != db.ReportOpen {
+		lines = append(lines, i18n.T(lang, "report.handled", r.Handler, i18n.T(lang, "report.status."+r.Status)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// getReportKeyboard returns buttons of actions on report
+func getReportKeyboard(lang string, r *db.Report) tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, action := range reportActions {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "report.button."+action),
+			getCallbackData(reportPrefix, r.ChatID, r.MessageID, action)))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row)
+}
+
+// getReportRecipients returns administrators and moderators of chat who receive reports privately
+func (s *Server) getReportRecipients(chatID int64) (ids []int64, err error) {
+	admins, err := s.Bot.GetChatAdministrators(tgbotapi.ChatConfig{ChatID: chatID})
+	if err != nil {
+		return
+	}
+	roles, err := db.GetChatRoles(chatID)
+	if err != nil {
+		return
+	}
+
+	seen := make(map[int]bool)
+	for _, admin := range admins {
+		if admin.User != nil && !admin.User.IsBot && !seen[admin.User.ID] {
+			seen[admin.User.ID] = true
+			ids = append(ids, int64(admin.User.ID))
+		}
+	}
+	for _, chatRole := range roles {
+		if chatRole.Role == db.RoleModerator && !seen[chatRole.UserID] {
+			seen[chatRole.UserID] = true
+			ids = append(ids, int64(chatRole.UserID))
+		}
+	}
+	return
+}
+
+// sendReport sends report with action buttons to admin chat or to admins privately,
+// it returns messages sent successfully
+func (s *Server) sendReport(r *db.Report, settings db.ReportSettings) (notices []db.ReportNotice) {
+	recipients := []int64{settings.ChatID}
+	if settings.ChatID == 0 {
+		var err error
+		if recipients, err = s.getReportRecipients(r.ChatID); err != nil {
+			log.Printf("Error in sendReport -> getReportRecipients: %s", err)
+			return
+		}
+	}
+
+	lang := getChatLanguage(r.ChatID)
+	text, keyboard := formatReport(lang, r), getReportKeyboard(lang, r)
+	for _, chatID := range recipients {
+		sent, err := s.sendKeyboard(text, chatID, 0, keyboard)
+		if err != nil {
+			// admin can't be messaged until the admin starts private chat with bot
+			log.Printf("Error in sendReport to %d: %s", chatID, err)
+			continue
+		}
+		notices = append(notices, db.ReportNotice{ChatID: chatID, MessageID: sent.MessageID})
+	}
+	return
+}
+
+// updateReportNotices replaces report messages of admins with handled report without buttons
+func (s *Server) updateReportNotices(r *db.Report) {
+	text := formatReport(getChatLanguage(r.ChatID), r)
+	for _, notice := range r.Notices {
+		if _, err := s.Bot.Send(tgbotapi.NewEditMessageText(notice.ChatID, notice.MessageID, text)); err != nil {
+			log.Printf("Error in updateReportNotices for %d: %s", notice.ChatID, err)
+		}
+	}
+}
+
+// Report command sends report on replied message to admin chat or to admins privately
+func (s *Server) Report(msg *tgbotapi.Message) {
+	lang := getMessageLanguage(msg)
+	source := msg.ReplyToMessage
+	if source == nil || source.From == nil {
+		s.SendError(i18n.T(lang, "report.usage"), msg)
+		return
+	}
+	if source.From.ID == s.Bot.Self.ID {
+		s.SendError(i18n.T(lang, "target.self"), msg)
+		return
+	}
+	if source.From.ID == msg.From.ID {
+		s.SendError(i18n.T(lang, "report.self"), msg)
+		return
+	}
+
+	settings, err := db.GetChatSettings(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in Report -> GetChatSettings: %s", err)
+		return
+	}
+
+	r := &db.Report{
+		ChatID:     msg.Chat.ID,
+		Chat:       getChatName(msg.Chat),
+		MessageID:  source.MessageID,
+		UserID:     source.From.ID,
+		User:       source.From.String(),
+		FirstName:  source.From.FirstName,
+		LastName:   source.From.LastName,
+		UserName:   source.From.UserName,
+		ReporterID: msg.From.ID,
+		Reporter:   msg.From.String(),
+		Reason:     strings.TrimSpace(msg.CommandArguments()),
+		Text:       truncateText(getMessageSummary(lang, source), reportTextLength),
+		Link:       s.getPermalink(source),
+		Date:       time.Now().Unix(),
+	}
+	ok, err := db.AddReport(r)
+	if err != nil {
+		log.Printf("Error in Report -> AddReport: %s", err)
+		return
+	}
+	if !ok {
+		s.SendError(i18n.T(lang, "report.duplicate"), msg)
+		return
+	}
+
+	notices := s.sendReport(r, settings.Report)
+	if len(notices) == 0 {
+		// the message can be reported again
+		if err = db.RemoveReport(r.ChatID, r.MessageID); err != nil {
+			log.Printf("Error in Report -> RemoveReport: %s", err)
+		}
+		s.SendError(i18n.T(lang, "report.failed"), msg)
+		return
+	}
+	if err = db.AddReportNotices(r.ChatID, r.MessageID, notices); err != nil {
+		log.Printf("Error in Report -> AddReportNotices: %s", err)
+	}
+	s.SendError(i18n.T(lang, "report.sent"), msg)
+}
+
+// reportCallback handles action of admin on report, args are chat ID, reported message ID and action
+func (s *Server) reportCallback(query *tgbotapi.CallbackQuery, args []string) {
+	if len(args) != 3 || query.Message == nil {
+		s.answerCallback(query, "", false)
+		return
+	}
+	lang := getLanguage(query.Message.Chat, query.From)
+	chatID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		s.answerCallback(query, "", false)
+		return
+	}
+	messageID, err := strconv.Atoi(args[1])
+	if err != nil {
+		s.answerCallback(query, "", false)
+		return
+	}
+	action := args[2]
+	switch action {
+	case db.ReportWarn, db.ReportMute, db.ReportBan, db.ReportDismiss:
+	default:
+		s.answerCallback(query, "", false)
+		return
+	}
+
+	chat, err := s.Bot.GetChat(tgbotapi.ChatConfig{ChatID: chatID})
+	if err != nil {
+		log.Printf("Error in reportCallback -> GetChat: %s", err)
+		s.answerCallback(query, i18n.T(lang, "report.chat_unavailable"), true)
+		return
+	}
+	role, err := s.getUserRole(query.From.ID, &chat)
+	if err != nil {
+		log.Printf("Error in reportCallback -> getUserRole: %s", err)
+		s.answerCallback(query, i18n.T(lang, "role.own_unknown"), true)
+		return
+	}
+	if role < RoleModerator {
+		s.answerCallback(query, i18n.T(lang, "command.role_required", RoleModerator.Name(lang)), true)
+		return
+	}
+
+	r, err := db.GetReport(chatID, messageID)
+	if err != nil {
+		log.Printf("Error in reportCallback -> GetReport: %s", err)
+		s.answerCallback(query, "", false)
+		return
+	}
+	if r == nil {
+		s.answerCallback(query, i18n.T(lang, "report.not_found"), true)
+		return
+	}
+	user, err := db.GetUserByID(r.UserID)
+	if err != nil {
+		// user is not in database, Telegram still knows the ID
+		user = &tgbotapi.User{ID: r.UserID, FirstName: r.FirstName, LastName: r.LastName, UserName: r.UserName}
+	}
+	if action != db.ReportDismiss {
+		targetRole, err := s.getUserRole(user.ID, &chat)
+		if err != nil {
+			log.Printf("Error in reportCallback -> getUserRole: %s, treat as member", err)
+			targetRole = RoleMember
+		}
+		if targetRole >= role {
+			s.answerCallback(query, i18n.T(lang, "role.target_protected", r.User, targetRole.Name(lang)), true)
+			return
+		}
+	}
+
+	// report is marked before action, so it is handled once if admins press buttons at the same time
+	handled, ok, err := db.HandleReport(chatID, messageID, action, query.From.ID, query.From.String(), time.Now().Unix())
+	if err != nil {
+		log.Printf("Error in reportCallback -> HandleReport: %s", err)
+		s.answerCallback(query, "", false)
+		return
+	}
+	if !ok {
+		if handled == nil {
+			s.answerCallback(query, i18n.T(lang, "report.not_found"), true)
+			return
+		}
+		s.updateReportNotices(handled)
+		s.answerCallback(query, i18n.T(lang, "report.handled", handled.Handler, i18n.T(lang, "report.status."+handled.Status)), true)
+		return
+	}
+
+	if actionErr := s.applyReportAction(&chat, user, query.From, r, action); actionErr != nil {
+		log.Printf("Error in reportCallback -> %s: %s", action, actionErr)
+		if err = db.ReopenReport(chatID, messageID); err != nil {
+			log.Printf("Error in reportCallback -> ReopenReport: %s", err)
+		}
+		s.answerCallback(query, i18n.T(lang, "restrict.failed", r.User, actionErr), true)
+		return
+	}
+	s.updateReportNotices(handled)
+	s.answerCallback(query, i18n.T(lang, "report.status."+action), false)
+}
+
+// applyReportAction warns, mutes or bans author of reported message, issuer is admin who handled report
+func (s *Server) applyReportAction(chat *tgbotapi.Chat, user, issuer *tgbotapi.User, r *db.Report, action string) (err error) {
+	lang := getLanguage(chat, nil)
+	reason := r.Reason
+	if reason == "" {
+		reason = i18n.T(lang, "report.default_reason")
+	}
+
+	var until time.Time
+	switch action {
+	case db.ReportWarn:
+		source, msgErr := db.GetMessage(chat.ID, r.MessageID)
+		if msgErr != nil {
+			source = &tgbotapi.Message{MessageID: r.MessageID, Chat: chat}
+		}
+		err = s.warnUser(chat, user, issuer, reason, source, 0)
+	case db.ReportMute:
+		if until, err = s.restrict(chat.ID, user, db.RestrictionMute, defaultMuteDuration, issuer, reason); err == nil {
+			s.SendMessage(formatRestriction(lang, db.RestrictionMute, user, until, reason, db.GetChatLocation(chat.ID)), chat.ID, 0)
+		}
+	case db.ReportBan:
+		if until, err = s.restrict(chat.ID, user, db.RestrictionBan, 0, issuer, reason); err == nil {
+			s.SendMessage(formatRestriction(lang, db.RestrictionBan, user, until, reason, db.GetChatLocation(chat.ID)), chat.ID, 0)
+		}
+	}
+	return
+}
+
+// ReportChat command shows or sets chat receiving reports of members, reports are sent to admins privately by default
+func (s *Server) ReportChat(msg *tgbotapi.Message) {
+	arg := strings.ToLower(strings.TrimSpace(msg.CommandArguments()))
+
+	settings, err := db.GetChatSettings(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in ReportChat -> GetChatSettings: %s", err)
+		return
+	}
+
+	lang := getMessageLanguage(msg)
+	if arg != "" {
+		if !s.checkRole(msg, RoleAdmin) {
+			return
+		}
+
+		if arg == "private" {
+			settings.Report.ChatID = 0
+		} else {
+			chatID, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil || chatID == msg.Chat.ID {
+				s.SendError(i18n.T(lang, "reportchat.usage"), msg)
+				return
+			}
+			// bot must be able to write to admin chat
+			linked := tgbotapi.NewMessage(chatID, i18n.T(getChatLanguage(chatID), "reportchat.linked", getChatName(msg.Chat)))
+			if _, err = s.Bot.Send(linked); err != nil {
+				log.Printf("Error in ReportChat -> Send: %s", err)
+				s.SendError(i18n.T(lang, "reportchat.failed", chatID), msg)
+				return
+			}
+			settings.Report.ChatID = chatID
+		}
+		if err = db.SaveChatSettings(settings); err != nil {
+			log.Printf("Error in ReportChat -> SaveChatSettings: %s", err)
+			return
+		}
+	}
+
+	if settings.Report.ChatID == 0 {
+		s.SendMessage(i18n.T(lang, "reportchat.private"), msg.Chat.ID, msg.MessageID)
+		return
+	}
+	s.SendMessage(i18n.T(lang, "reportchat.chat", settings.Report.ChatID), msg.Chat.ID, msg.MessageID)
+}
@@ -0,0 +1,327 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/escalation"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+const (
+	defaultMuteDuration = time.Hour
+	schedulerInterval   = 30 * time.Second
+)
+
+// parseDuration parses durations like 30m, 2d or 1d12h
+func parseDuration(str string) (d time.Duration, err error) {
+	duration, err := escalation.ParseDuration(str)
+	if err != nil {
+		return 0, i18n.Errorf("duration.invalid", str)
+	}
+	return time.Duration(duration), nil
+}
+
+// parseDurationArgs returns duration from the first of arguments if it is duration and the rest of arguments
+func parseDurationArgs(args string) (d time.Duration, rest string, err error) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 || !escalation.IsDuration(fields[0]) {
+		return 0, strings.TrimSpace(args), nil
+	}
+	d, err = parseDuration(fields[0])
+	return d, strings.Join(fields[1:], " "), err
+}
+
+// checkResponse returns error for failed Telegram request
+func checkResponse(resp tgbotapi.APIResponse, err error) error {
+	if err != nil {
+		return err
+	}
+	if !resp.Ok {
+		return fmt.Errorf("code=%d description: %s", resp.ErrorCode, resp.Description)
+	}
+	return nil
+}
+
+// banUser bans user in chat until time, zero time means forever
+func (s *Server) banUser(chatID int64, userID int, until time.Time) error {
+	config := tgbotapi.KickChatMemberConfig{
+		ChatMemberConfig: tgbotapi.ChatMemberConfig{ChatID: chatID, UserID: userID},
+	}
+	if !until.IsZero() {
+		config.UntilDate = until.Unix()
+	}
+	return checkResponse(s.Bot.KickChatMember(config))
+}
+
+// unbanUser lifts ban of user in chat
+func (s *Server) unbanUser(chatID int64, userID int) error {
+	return checkResponse(s.Bot.UnbanChatMember(tgbotapi.ChatMemberConfig{ChatID: chatID, UserID: userID}))
+}
+
+// kickOut removes user from chat, the user can join again
+func (s *Server) kickOut(chatID int64, userID int) error {
+	if err := s.banUser(chatID, userID, time.Time{}); err != nil {
+		return err
+	}
+	return s.unbanUser(chatID, userID)
+}
+
+// chatPermissions are default permissions of chat members, Chat of bot library has no field for them
+type chatPermissions struct {
+	CanSendMessages       bool `json:"can_send_messages"`
+	CanSendMediaMessages  bool `json:"can_send_media_messages"`
+	CanSendOtherMessages  bool `json:"can_send_other_messages"`
+	CanAddWebPagePreviews bool `json:"can_add_web_page_previews"`
+}
+
+// getChatPermissions returns default permissions of chat members, everything is allowed if chat has no defaults
+func (s *Server) getChatPermissions(chatID int64) (permissions chatPermissions, err error) {
+	v := url.Values{}
+	v.Add("chat_id", strconv.FormatInt(chatID, 10))
+	resp, err := s.Bot.MakeRequest("getChat", v)
+	if err != nil {
+		return
+	}
+
+	var chat struct {
+		Permissions *chatPermissions `json:"permissions"`
+	}
+	if err = json.Unmarshal(resp.Result, &chat); err != nil {
+		return
+	}
+	if chat.Permissions == nil {
+		return chatPermissions{true, true, true, true}, nil
+	}
+	return *chat.Permissions, nil
+}
+
+// restrictUser forbids user to send messages in chat until time, zero time means forever,
+// if canSend is true user gets default permissions of chat members instead
+func (s *Server) restrictUser(chatID int64, userID int, until time.Time, canSend bool) error {
+	var permissions chatPermissions
+	if canSend {
+		var err error
+		if permissions, err = s.getChatPermissions(chatID); err != nil {
+			return err
+		}
+	}
+
+	config := tgbotapi.RestrictChatMemberConfig{
+		ChatMemberConfig:      tgbotapi.ChatMemberConfig{ChatID: chatID, UserID: userID},
+		CanSendMessages:       &permissions.CanSendMessages,
+		CanSendMediaMessages:  &permissions.CanSendMediaMessages,
+		CanSendOtherMessages:  &permissions.CanSendOtherMessages,
+		CanAddWebPagePreviews: &permissions.CanAddWebPagePreviews,
+	}
+	if !until.IsZero() {
+		config.UntilDate = until.Unix()
+	}
+	return checkResponse(s.Bot.RestrictChatMember(config))
+}
+
+// restrict mutes or bans user in chat for duration, zero duration means forever,
+// temporary restrictions are stored to be lifted by scheduler
+func (s *Server) restrict(chatID int64, user *tgbotapi.User, kind string, d time.Duration, issuer *tgbotapi.User, reason string) (until time.Time, err error) {
+	now := time.Now()
+	if d > 0 {
+		until = now.Add(d)
+	}
+
+	switch kind {
+	case db.RestrictionMute, db.RestrictionReadOnly:
+		if err = s.restrictUser(chatID, user.ID, until, false); err == nil {
+			// new restriction of member replaces the previous one of other kind
+			err = db.RemoveRestriction(chatID, user.ID, getOtherMuteKind(kind))
+		}
+	case db.RestrictionBan:
+		err = s.banUser(chatID, user.ID, until)
+	default:
+		err = fmt.Errorf("Unknown restriction kind %s", kind)
+	}
+	if err != nil {
+		return
+	}
+
+	if until.IsZero() {
+		// permanent restriction replaces temporary one
+		err = db.RemoveRestriction(chatID, user.ID, kind)
+		return
+	}
+	r := &db.Restriction{
+		ChatID: chatID,
+		UserID: user.ID,
+		User:   user.String(),
+		Kind:   kind,
+		Until:  until.Unix(),
+		Reason: reason,
+		Date:   now.Unix(),
+	}
+	if issuer != nil {
+		r.IssuerID = issuer.ID
+	}
+	err = db.SaveRestriction(r)
+	return
+}
+
+// getOtherMuteKind returns read-only kind for mute and mute kind for read-only
+func getOtherMuteKind(kind string) string {
+	if kind == db.RestrictionMute {
+		return db.RestrictionReadOnly
+	}
+	return db.RestrictionMute
+}
+
+// Mute command forbids user to send messages for duration, one hour by default
+func (s *Server) Mute(msg *tgbotapi.Message) {
+	s.restrictCommand(msg, db.RestrictionMute, defaultMuteDuration, false)
+}
+
+// ReadOnly command switches user to read-only mode until /unmute or for duration
+func (s *Server) ReadOnly(msg *tgbotapi.Message) {
+	s.restrictCommand(msg, db.RestrictionReadOnly, 0, false)
+}
+
+// TempBan command bans user for duration
+func (s *Server) TempBan(msg *tgbotapi.Message) {
+	s.restrictCommand(msg, db.RestrictionBan, 0, true)
+}
+
+func (s *Server) restrictCommand(msg *tgbotapi.Message, kind string, defaultDuration time.Duration, durationRequired bool) {
+	user, args, ok := s.getTarget(msg)
+	if !ok {
+		return
+	}
+	if !s.checkTargetRole(msg, user) {
+		return
+	}
+
+	lang := getMessageLanguage(msg)
+	d, reason, err := parseDurationArgs(args)
+	if err != nil {
+		s.SendError(i18n.ErrorText(lang, err), msg)
+		return
+	}
+	if d == 0 {
+		if durationRequired {
+			s.SendError(i18n.T(lang, "restrict.duration_required", msg.Command(), user.String()), msg)
+			return
+		}
+		d = defaultDuration
+	}
+
+	until, err := s.restrict(msg.Chat.ID, user, kind, d, msg.From, reason)
+	if err != nil {
+		log.Printf("Error in restrict %s: %s", kind, err)
+		s.SendError(i18n.T(lang, "restrict.failed", user.String(), err), msg)
+		return
+	}
+	s.SendMessage(formatRestriction(lang, kind, user, until, reason, db.GetChatLocation(msg.Chat.ID)), msg.Chat.ID, msg.MessageID)
+}
+
+// Unmute command allows user to send messages again, it lifts mute and read-only mode
+func (s *Server) Unmute(msg *tgbotapi.Message) {
+	user, _, ok := s.getTarget(msg)
+	if !ok {
+		return
+	}
+	if !s.checkTargetRole(msg, user) {
+		return
+	}
+
+	if err := s.restrictUser(msg.Chat.ID, user.ID, time.Time{}, true); err != nil {
+		log.Printf("Error in Unmute -> restrictUser: %s", err)
+		s.SendError(i18n.T(getMessageLanguage(msg), "restrict.lift_failed", user.String(), err), msg)
+		return
+	}
+	for _, kind := range []string{db.RestrictionMute, db.RestrictionReadOnly} {
+		if err := db.RemoveRestriction(msg.Chat.ID, user.ID, kind); err != nil {
+			log.Printf("Error in Unmute -> RemoveRestriction: %s", err)
+		}
+	}
+	s.SendError(i18n.T(getMessageLanguage(msg), "restrict.lifted", user.String()), msg)
+}
+
+func formatRestriction(lang, kind string, user *tgbotapi.User, until time.Time, reason string, loc *time.Location) string {
+	var text string
+	switch kind {
+	case db.RestrictionBan:
+		text = i18n.T(lang, "restrict.banned", user.String())
+	case db.RestrictionReadOnly:
+		text = i18n.T(lang, "restrict.readonly", user.String())
+	default:
+		text = i18n.T(lang, "restrict.muted", user.String())
+	}
+	if until.IsZero() {
+		text += i18n.T(lang, "restrict.forever")
+	} else {
+		text += i18n.T(lang, "restrict.until", until.In(loc).Format("02.01.2006 15:04"))
+	}
+	if reason != "" {
+		text += i18n.T(lang, "reason", reason)
+	}
+	return text
+}
+
+// RunScheduler lifts expired temporary restrictions, removes users who didn't pass captcha in time
+// and deletes expired bot messages, tasks expired while bot was stopped are handled at start,
+// also it frees memory of flood limiter
+func (s *Server) RunScheduler() {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+	for {
+		s.liftExpiredRestrictions()
+		s.expireChallenges()
+		s.deleteExpiredMessages()
+		if s.FloodLimiter != nil {
+			s.FloodLimiter.Cleanup()
+		}
+		<-ticker.C
+	}
+}
+
+func (s *Server) liftExpiredRestrictions() {
+	restrictions, err := db.GetExpiredRestrictions(time.Now())
+	if err != nil {
+		log.Printf("Error in GetExpiredRestrictions: %s", err)
+		return
+	}
+
+	for _, r := range restrictions {
+		switch r.Kind {
+		case db.RestrictionMute, db.RestrictionReadOnly:
+			err = s.restrictUser(r.ChatID, r.UserID, time.Time{}, true)
+		case db.RestrictionBan:
+			err = s.liftBan(r.ChatID, r.UserID)
+		}
+		if err != nil {
+			log.Printf("Error in lift %s of user %s in chat %d: %s", r.Kind, r.User, r.ChatID, err)
+			continue
+		}
+		if err = db.RemoveRestriction(r.ChatID, r.UserID, r.Kind); err != nil {
+			log.Printf("Error in RemoveRestriction: %s", err)
+			continue
+		}
+		log.Printf("Restriction %s of user %s in chat %d lifted", r.Kind, r.User, r.ChatID)
+	}
+}
+
+// liftBan unbans user if user is still banned, unban of chat member removes the user from chat
+func (s *Server) liftBan(chatID int64, userID int) error {
+	member, err := s.Bot.GetChatMember(tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: userID})
+	if err != nil {
+		return err
+	}
+	if !member.WasKicked() {
+		return nil
+	}
+	return s.unbanUser(chatID, userID)
+}
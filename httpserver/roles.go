@@ -0,0 +1,148 @@
+package httpserver
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// getUserRole returns role of user in chat: owner and administrators are taken from Telegram,
+// moderators and trusted members are delegated by administrators and stored in database
+func (s *Server) getUserRole(userID int, chat *tgbotapi.Chat) (role Role, err error) {
+	if chat == nil {
+		return RoleMember, fmt.Errorf("Chat pointer is nil")
+	}
+	if chat.IsPrivate() {
+		// user owns private chat with bot
+		return RoleOwner, nil
+	}
+
+	member, err := s.Bot.GetChatMember(tgbotapi.ChatConfigWithUser{ChatID: chat.ID, UserID: userID})
+	if err != nil {
+		return RoleMember, err
+	}
+	switch {
+	case member.IsCreator():
+		return RoleOwner, nil
+	case member.IsAdministrator(), chat.AllMembersAreAdmins:
+		return RoleAdmin, nil
+	}
+
+	chatRole, err := db.GetChatRole(chat.ID, userID)
+	if err != nil {
+		return RoleMember, err
+	}
+	return getDelegatedRole(chatRole.Role), nil
+}
+
+// getDelegatedRole returns role by name of role stored in database
+func getDelegatedRole(name string) Role {
+	switch name {
+	case db.RoleModerator:
+		return RoleModerator
+	case db.RoleTrusted:
+		return RoleTrusted
+	}
+	return RoleMember
+}
+
+// checkTargetRole returns true if target user has lower role than message author, error message is sent otherwise
+func (s *Server) checkTargetRole(msg *tgbotapi.Message, user *tgbotapi.User) bool {
+	userRole, err := s.getUserRole(msg.From.ID, msg.Chat)
+	if err != nil {
+		log.Printf("Error in checkTargetRole -> getUserRole: %s", err)
+		s.SendError(i18n.T(getMessageLanguage(msg), "role.own_unknown"), msg)
+		return false
+	}
+	targetRole, err := s.getUserRole(user.ID, msg.Chat)
+	if err != nil {
+		// user who left the chat isn't a chat member anymore
+		log.Printf("Error in checkTargetRole -> getUserRole for %d, treat as member: %s", user.ID, err)
+		targetRole = RoleMember
+	}
+	if targetRole >= userRole {
+		lang := getMessageLanguage(msg)
+		s.SendError(i18n.T(lang, "role.target_protected", user.String(), targetRole.Name(lang)), msg)
+		return false
+	}
+	return true
+}
+
+// Promote command delegates moderator or trusted role to user
+func (s *Server) Promote(msg *tgbotapi.Message) {
+	user, args, ok := s.getTarget(msg)
+	if !ok {
+		return
+	}
+
+	name := strings.ToLower(strings.TrimSpace(args))
+	if name == "" {
+		name = db.RoleModerator
+	}
+	if name != db.RoleModerator && name != db.RoleTrusted {
+		lang := getMessageLanguage(msg)
+		s.SendError(i18n.T(lang, "roles.promote_usage", i18n.T(lang, "cmd.promote.args")), msg)
+		return
+	}
+	if !s.checkTargetRole(msg, user) {
+		return
+	}
+
+	chatRole := &db.ChatRole{
+		ChatID:    msg.Chat.ID,
+		UserID:    user.ID,
+		User:      user.String(),
+		Role:      name,
+		GrantedBy: msg.From.ID,
+		Date:      time.Now().Unix(),
+	}
+	if err := db.SetChatRole(chatRole); err != nil {
+		log.Printf("Error in Promote -> SetChatRole: %s", err)
+		return
+	}
+	lang := getMessageLanguage(msg)
+	s.SendError(i18n.T(lang, "roles.promoted", user.String(), getDelegatedRole(name).Name(lang)), msg)
+}
+
+// Demote command removes delegated role from user
+func (s *Server) Demote(msg *tgbotapi.Message) {
+	user, _, ok := s.getTarget(msg)
+	if !ok {
+		return
+	}
+	if !s.checkTargetRole(msg, user) {
+		return
+	}
+
+	if err := db.RemoveChatRole(msg.Chat.ID, user.ID); err != nil {
+		log.Printf("Error in Demote -> RemoveChatRole: %s", err)
+		return
+	}
+	s.SendError(i18n.T(getMessageLanguage(msg), "roles.demoted", user.String()), msg)
+}
+
+// Roles command shows delegated roles of chat
+func (s *Server) Roles(msg *tgbotapi.Message) {
+	roles, err := db.GetChatRoles(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in Roles -> GetChatRoles: %s", err)
+		return
+	}
+	lang := getMessageLanguage(msg)
+	if len(roles) == 0 {
+		s.SendError(i18n.T(lang, "roles.empty"), msg)
+		return
+	}
+
+	lines := []string{i18n.T(lang, "roles.title")}
+	for _, chatRole := range roles {
+		lines = append(lines, fmt.Sprintf("%s - %s", chatRole.User, getDelegatedRole(chatRole.Role).Name(lang)))
+	}
+	s.SendMessage(strings.Join(lines, "\n"), msg.Chat.ID, msg.MessageID)
+}
@@ -0,0 +1,321 @@
+package httpserver
+
+import (
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/escalation"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+const (
+	spamLogSize    = 10
+	spamLogMaxSize = 50
+	spamTextLength = 100
+)
+
+var (
+	inviteRegexp = regexp.MustCompile(`(?i)(\b(t|telegram)\.(me|dog)/\S+|tg://join)`)
+	// hostRegexp matches links with or without scheme, submatch is a host of link
+	hostRegexp = regexp.MustCompile(`(?i)(?:[a-z][a-z0-9+.-]*://)?((?:[\p{L}\d-]+\.)+\p{L}{2,})(?:[/?#]\S*[^\s.,!?;:)])?`)
+	tgRegexp   = regexp.MustCompile(`(?i)\btg://\S+`)
+)
+
+// spamKinds are kinds of content blocked for new members
+var spamKinds = map[string]bool{
+	db.SpamKindLink:    true,
+	db.SpamKindInvite:  true,
+	db.SpamKindForward: true,
+	db.SpamKindMedia:   true,
+}
+
+// getSpamKind returns kind of content blocked for new members found in message
+func getSpamKind(msg *tgbotapi.Message, kinds []string) (kind string, ok bool) {
+	text := msg.Text + "\n" + msg.Caption
+	for _, kind = range kinds {
+		switch kind {
+		case db.SpamKindInvite:
+			ok = inviteRegexp.MatchString(text) || hasEntityURL(msg, inviteRegexp)
+		case db.SpamKindLink:
+			ok = linkRegexp.MatchString(text) || hasEntityURL(msg, nil)
+		case db.SpamKindForward:
+			ok = msg.ForwardFromChat != nil && msg.ForwardFromChat.IsChannel()
+		case db.SpamKindMedia:
+			ok = msg.Photo != nil || msg.Video != nil || msg.Animation != nil || msg.Document != nil ||
+				msg.Audio != nil || msg.Voice != nil || msg.VideoNote != nil
+		}
+		if ok {
+			return
+		}
+	}
+	return "", false
+}
+
+// hasEntityURL returns true if message has link entity, link must match re if it isn't nil
+func hasEntityURL(msg *tgbotapi.Message, re *regexp.Regexp) bool {
+	if msg.Entities == nil {
+		return false
+	}
+	for _, entity := range *msg.Entities {
+		var link string
+		switch entity.Type {
+		case "url":
+			link = getEntityText(msg.Text, entity)
+		case "text_link":
+			link = entity.URL
+		default:
+			continue
+		}
+		if re == nil || re.MatchString(link) {
+			return true
+		}
+	}
+	return false
+}
+
+// defangLinks replaces links in text with their hosts written so that Telegram doesn't make them clickable,
+// blocked links are not posted to chat again
+func defangLinks(text string) string {
+	text = tgRegexp.ReplaceAllString(text, "tg://…")
+	return hostRegexp.ReplaceAllStringFunc(text, func(link string) string {
+		host := hostRegexp.FindStringSubmatch(link)[1]
+		return strings.Replace(host, ".", "[.]", -1)
+	})
+}
+
+// isNewMember returns true if user has few messages in chat or the first message of user is recent
+func isNewMember(chatID int64, userID int, settings db.SpamSettings) (ok bool, err error) {
+	count, first, err := db.GetUserHistory(chatID, userID)
+	if err != nil {
+		return
+	}
+	return count < settings.GetMinMessages() || first.IsZero() || time.Since(first) < settings.GetMinAge(), nil
+}
+
+// CheckSpam blocks links, invites, forwarded channel posts and media of new members by chat settings,
+// trusted members are not checked
+func (s *Server) CheckSpam(msg *tgbotapi.Message) {
+	if msg.From == nil || msg.Chat.IsPrivate() {
+		return
+	}
+	settings, err := db.GetChatSettings(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in CheckSpam -> GetChatSettings: %s", err)
+		return
+	}
+	if !settings.Spam.Enabled {
+		return
+	}
+
+	kind, ok := getSpamKind(msg, settings.Spam.GetKinds())
+	if !ok {
+		return
+	}
+	if ok, err = isNewMember(msg.Chat.ID, msg.From.ID, settings.Spam); err != nil {
+		log.Printf("Error in CheckSpam -> isNewMember: %s", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	role, err := s.getUserRole(msg.From.ID, msg.Chat)
+	if err != nil {
+		log.Printf("Error in CheckSpam -> getUserRole: %s", err)
+		return
+	}
+	if role >= RoleTrusted {
+		return
+	}
+
+	lang := getLanguage(msg.Chat, nil)
+	reason := i18n.T(lang, "spam.reason", i18n.T(lang, "spam.kind."+kind))
+	log.Printf("[%s] %s blocked in chat %d", msg.From.String(), kind, msg.Chat.ID)
+	var applied []string
+	for _, action := range settings.Spam.GetActions() {
+		var (
+			until time.Time
+			err   error
+		)
+		switch action {
+		case db.SpamActionDelete:
+			if err = s.removeMessage(msg, reason, 0); err == nil {
+				s.SendMessage(i18n.T(lang, "spam.removed", msg.From.String())+i18n.T(lang, "reason", reason), msg.Chat.ID, 0)
+			}
+		case db.SpamActionWarn:
+			err = s.warnUser(msg.Chat, msg.From, &s.Bot.Self, reason, msg, 0)
+		case db.SpamActionMute:
+			if until, err = s.restrict(msg.Chat.ID, msg.From, db.RestrictionMute, settings.Spam.GetMuteDuration(), nil, reason); err == nil {
+				s.SendMessage(formatRestriction(lang, db.RestrictionMute, msg.From, until, reason, db.GetChatLocation(msg.Chat.ID)), msg.Chat.ID, 0)
+			}
+		case db.SpamActionBan:
+			if until, err = s.restrict(msg.Chat.ID, msg.From, db.RestrictionBan, 0, nil, reason); err == nil {
+				s.SendMessage(formatRestriction(lang, db.RestrictionBan, msg.From, until, reason, db.GetChatLocation(msg.Chat.ID)), msg.Chat.ID, 0)
+			}
+		}
+		if err != nil {
+			log.Printf("Error in CheckSpam -> %s: %s", action, err)
+			continue
+		}
+		applied = append(applied, action)
+	}
+
+	entry := &db.SpamLogEntry{
+		ChatID:    msg.Chat.ID,
+		UserID:    msg.From.ID,
+		User:      msg.From.String(),
+		MessageID: msg.MessageID,
+		Kind:      kind,
+		Text:      truncateText(strings.TrimSpace(msg.Text+" "+msg.Caption), spamTextLength),
+		Actions:   applied,
+	}
+	if err = db.AddSpamLogEntry(entry); err != nil {
+		log.Printf("Error in CheckSpam -> AddSpamLogEntry: %s", err)
+	}
+}
+
+// getSpamDescription returns human readable spam filter settings
+func getSpamDescription(lang string, settings db.SpamSettings) string {
+	state := i18n.T(lang, "spam.off")
+	if settings.Enabled {
+		state = i18n.T(lang, "spam.on")
+	}
+	var kinds []string
+	for _, kind := range settings.GetKinds() {
+		kinds = append(kinds, i18n.T(lang, "spam.kind."+kind))
+	}
+	var actions []string
+	for _, action := range settings.GetActions() {
+		switch action {
+		case db.SpamActionDelete:
+			actions = append(actions, i18n.T(lang, "action.delete_message"))
+		case db.SpamActionWarn:
+			actions = append(actions, i18n.T(lang, "action.warn"))
+		case db.SpamActionMute:
+			actions = append(actions, i18n.T(lang, "action.mute", escalation.Duration(settings.GetMuteDuration())))
+		case db.SpamActionBan:
+			actions = append(actions, i18n.T(lang, "action.ban"))
+		}
+	}
+	messages, hours := settings.GetMinMessages(), int(settings.GetMinAge()/time.Hour)
+	return i18n.T(lang, "spam.description", state, i18n.N(lang, "messages", messages, messages), i18n.N(lang, "hours", hours, hours),
+		strings.Join(kinds, ", "), strings.Join(actions, ", "))
+}
+
+// Spam command shows or changes spam filter settings of chat
+func (s *Server) Spam(msg *tgbotapi.Message) {
+	args := strings.Fields(strings.ToLower(msg.CommandArguments()))
+
+	settings, err := db.GetChatSettings(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in Spam -> GetChatSettings: %s", err)
+		return
+	}
+
+	lang := getMessageLanguage(msg)
+	if len(args) == 0 {
+		s.SendMessage(getSpamDescription(lang, settings.Spam), msg.Chat.ID, msg.MessageID)
+		return
+	}
+
+	if !s.checkRole(msg, RoleAdmin) {
+		return
+	}
+
+	switch {
+	case args[0] == "on" && len(args) == 1:
+		settings.Spam.Enabled = true
+	case args[0] == "off" && len(args) == 1:
+		settings.Spam.Enabled = false
+	case args[0] == "new" && len(args) == 3:
+		messages, err := strconv.Atoi(args[1])
+		if err != nil || messages <= 0 {
+			s.SendError(i18n.T(lang, "spam.usage"), msg)
+			return
+		}
+		hours, err := strconv.Atoi(args[2])
+		if err != nil || hours <= 0 {
+			s.SendError(i18n.T(lang, "spam.usage"), msg)
+			return
+		}
+		settings.Spam.MinMessages, settings.Spam.MinHours = messages, hours
+	case args[0] == "block" && len(args) > 1:
+		for _, kind := range args[1:] {
+			if !spamKinds[kind] {
+				s.SendError(i18n.T(lang, "spam.usage"), msg)
+				return
+			}
+		}
+		settings.Spam.Kinds = args[1:]
+	case args[0] == "action" && len(args) > 1:
+		for _, action := range args[1:] {
+			switch action {
+			case db.SpamActionDelete, db.SpamActionWarn, db.SpamActionMute, db.SpamActionBan:
+			default:
+				s.SendError(i18n.T(lang, "spam.usage"), msg)
+				return
+			}
+		}
+		settings.Spam.Actions = args[1:]
+	case args[0] == "mute" && len(args) == 2:
+		d, err := parseDuration(args[1])
+		if err != nil {
+			s.SendError(i18n.ErrorText(lang, err), msg)
+			return
+		}
+		settings.Spam.MuteDuration = escalation.Duration(d)
+	default:
+		s.SendError(i18n.T(lang, "spam.usage"), msg)
+		return
+	}
+
+	if err = db.SaveChatSettings(settings); err != nil {
+		log.Printf("Error in Spam -> SaveChatSettings: %s", err)
+		return
+	}
+	s.SendMessage(getSpamDescription(lang, settings.Spam), msg.Chat.ID, msg.MessageID)
+}
+
+// SpamLog command shows the last entries of spam log of chat
+func (s *Server) SpamLog(msg *tgbotapi.Message) {
+	lang := getMessageLanguage(msg)
+	limit := spamLogSize
+	if arg := strings.TrimSpace(msg.CommandArguments()); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			s.SendError(i18n.T(lang, "spamlog.usage"), msg)
+			return
+		}
+		if n > spamLogMaxSize {
+			n = spamLogMaxSize
+		}
+		limit = n
+	}
+
+	entries, err := db.GetSpamLog(msg.Chat.ID, limit)
+	if err != nil {
+		log.Printf("Error in SpamLog -> GetSpamLog: %s", err)
+		return
+	}
+	if len(entries) == 0 {
+		s.SendError(i18n.T(lang, "spamlog.empty"), msg)
+		return
+	}
+
+	loc := db.GetChatLocation(msg.Chat.ID)
+	lines := []string{i18n.T(lang, "spamlog.title")}
+	for _, entry := range entries {
+		actions := strings.Join(entry.Actions, ", ")
+		if actions == "" {
+			actions = i18n.T(lang, "none")
+		}
+		lines = append(lines, i18n.T(lang, "spamlog.entry", entry.ID, time.Unix(entry.Date, 0).In(loc).Format("02.01.2006 15:04"),
+			entry.User, i18n.T(lang, "spam.kind."+entry.Kind), defangLinks(entry.Text), actions))
+	}
+	s.SendMessage(strings.Join(lines, "\n"), msg.Chat.ID, msg.MessageID)
+}
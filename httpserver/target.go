@@ -0,0 +1,134 @@
+package httpserver
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// getTarget returns user targeted by moderation command and the rest of command arguments,
+// error message is sent to chat if target is not resolved
+func (s *Server) getTarget(msg *tgbotapi.Message) (user *tgbotapi.User, args string, ok bool) {
+	user, args, err := resolveTarget(msg)
+	if err != nil {
+		s.SendError(i18n.ErrorText(getMessageLanguage(msg), err), msg)
+		return nil, "", false
+	}
+	if user.ID == s.Bot.Self.ID {
+		s.SendError(i18n.T(getMessageLanguage(msg), "target.self"), msg)
+		return nil, "", false
+	}
+	return user, args, true
+}
+
+// resolveTarget returns user targeted by command. Target is searched in order:
+// author of replied message, mention as the first command argument, numeric user ID, @username, first and last name
+func resolveTarget(msg *tgbotapi.Message) (user *tgbotapi.User, args string, err error) {
+	args = strings.TrimSpace(msg.CommandArguments())
+
+	if msg.ReplyToMessage != nil && msg.ReplyToMessage.From != nil {
+		return msg.ReplyToMessage.From, args, nil
+	}
+
+	if msg.Entities != nil {
+		first := getFirstArgumentOffset(msg)
+		for _, entity := range *msg.Entities {
+			// mention in the rest of arguments is a part of reason
+			if entity.Offset != first {
+				continue
+			}
+			switch entity.Type {
+			case "text_mention":
+				if entity.User != nil {
+					return entity.User, getTextAfterEntity(msg.Text, entity), nil
+				}
+			case "mention":
+				user, err = findUser(getEntityText(msg.Text, entity))
+				return user, getTextAfterEntity(msg.Text, entity), err
+			}
+		}
+	}
+
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return nil, "", i18n.Errorf("target.usage")
+	}
+
+	if userID, convErr := strconv.Atoi(fields[0]); convErr == nil {
+		if user, err = db.GetUserByID(userID); err != nil {
+			// user is not in database yet, Telegram may still know the ID
+			user = &tgbotapi.User{ID: userID, FirstName: fields[0]}
+		}
+		return user, strings.Join(fields[1:], " "), nil
+	}
+
+	if strings.HasPrefix(fields[0], "@") {
+		user, err = findUser(fields[0])
+		return user, strings.Join(fields[1:], " "), err
+	}
+
+	user, err = findUser(args)
+	return user, "", err
+}
+
+// findUser returns user found in database by @username or name, errors are converted to messages for chat
+func findUser(name string) (user *tgbotapi.User, err error) {
+	user, err = db.GetUser(name)
+	if err != nil {
+		errStrings := strings.Split(err.Error(), "\n")
+		switch errStrings[0] {
+		case "User not found":
+			return nil, i18n.Errorf("target.not_found", name)
+		case "Many users":
+			return nil, i18n.Errorf("target.many", strings.Join(errStrings[1:], "\n"))
+		}
+		return nil, i18n.Errorf("target.error", err)
+	}
+	if user == nil {
+		return nil, i18n.Errorf("target.not_found", name)
+	}
+	return
+}
+
+// getEntityText returns text of entity, entity offsets are in UTF-16 code units
+func getEntityText(text string, entity tgbotapi.MessageEntity) string {
+	units := utf16.Encode([]rune(text))
+	if entity.Offset < 0 || entity.Offset+entity.Length > len(units) {
+		return ""
+	}
+	return string(utf16.Decode(units[entity.Offset : entity.Offset+entity.Length]))
+}
+
+// getFirstArgumentOffset returns offset of the first command argument in UTF-16 code units
+func getFirstArgumentOffset(msg *tgbotapi.Message) int {
+	units := utf16.Encode([]rune(msg.Text))
+	offset := 0
+	if msg.Entities != nil {
+		for _, entity := range *msg.Entities {
+			if entity.Type == "bot_command" && entity.Offset == 0 {
+				offset = entity.Length
+				break
+			}
+		}
+	}
+	for offset < len(units) && unicode.IsSpace(rune(units[offset])) {
+		offset++
+	}
+	return offset
+}
+
+// getTextAfterEntity returns trimmed text after entity
+func getTextAfterEntity(text string, entity tgbotapi.MessageEntity) string {
+	units := utf16.Encode([]rune(text))
+	end := entity.Offset + entity.Length
+	if end > len(units) {
+		return ""
+	}
+	return strings.TrimSpace(string(utf16.Decode(units[end:])))
+}
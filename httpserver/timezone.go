@@ -0,0 +1,73 @@
+package httpserver
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+const (
+	timezoneParam  = "tz"
+	timezoneCookie = "tz"
+	timezoneMaxAge = 365 * 24 * 60 * 60
+)
+
+// getLocation returns timezone for web viewer, it is taken from query param tz
+// (and stored to cookie) or cookie tz, otherwise chat timezone used
+func (s *Server) getLocation(c *gin.Context, chatID int64) *time.Location {
+	if name, ok := c.GetQuery(timezoneParam); ok {
+		if name == "" {
+			// reset viewer timezone
+			c.SetCookie(timezoneCookie, "", -1, "/", "", false, true)
+			return db.GetChatLocation(chatID)
+		}
+		if loc, err := time.LoadLocation(name); err == nil {
+			c.SetCookie(timezoneCookie, name, timezoneMaxAge, "/", "", false, true)
+			return loc
+		}
+		log.Printf("Wrong timezone [%s] in query", name)
+	}
+	if name, err := c.Cookie(timezoneCookie); err == nil && name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return db.GetChatLocation(chatID)
+}
+
+func getTimezoneInfo(lang string, loc *time.Location) string {
+	return fmt.Sprintf(`<p class="timezone">%s (<a href="?%s=">%s</a>)</p>`,
+		i18n.T(lang, "web.timezone", formatMessage(loc.String())), timezoneParam, i18n.T(lang, "web.reset"))
+}
+
+// Timezone command shows or sets chat timezone
+func (s *Server) Timezone(msg *tgbotapi.Message) {
+	name := strings.TrimSpace(msg.CommandArguments())
+	lang := getMessageLanguage(msg)
+	if name == "" {
+		s.SendMessage(i18n.T(lang, "timezone.current", db.GetChatLocation(msg.Chat.ID)), msg.Chat.ID, msg.MessageID)
+		return
+	}
+
+	if !s.checkRole(msg, RoleAdmin) {
+		return
+	}
+
+	if name == "default" {
+		name = ""
+	}
+	loc, err := db.SetChatTimezone(msg.Chat.ID, name)
+	if err != nil {
+		log.Printf("Error in SetChatTimezone: %s", err)
+		s.SendError(i18n.T(lang, "timezone.failed", name), msg)
+		return
+	}
+	s.SendMessage(i18n.T(lang, "timezone.current", loc), msg.Chat.ID, msg.MessageID)
+}
@@ -0,0 +1,247 @@
+package httpserver
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/escalation"
+	"github.com/elemc/gotelegrambot/i18n"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// WarnAdd command warns user, sanctions are applied by warn policy of chat
+func (s *Server) WarnAdd(msg *tgbotapi.Message) {
+	user, reason, ok := s.getTarget(msg)
+	if !ok {
+		return
+	}
+	if user.ID == msg.From.ID {
+		s.SendError(i18n.T(getMessageLanguage(msg), "warn.self"), msg)
+		return
+	}
+	if !s.checkTargetRole(msg, user) {
+		return
+	}
+
+	source := msg
+	if msg.ReplyToMessage != nil {
+		source = msg.ReplyToMessage
+	}
+	if err := s.warnUser(msg.Chat, user, msg.From, reason, source, msg.MessageID); err != nil {
+		log.Printf("Error in WarnAdd -> warnUser: %s", err)
+	}
+}
+
+// warnUser issues warning to user for source message and applies warn policy of chat,
+// replyID is a message for bot replies or 0
+func (s *Server) warnUser(chat *tgbotapi.Chat, user, issuer *tgbotapi.User, reason string, source *tgbotapi.Message, replyID int) (err error) {
+	settings, err := db.GetChatSettings(chat.ID)
+	if err != nil {
+		return fmt.Errorf("GetChatSettings: %s", err)
+	}
+
+	now := time.Now()
+	w := &db.Warning{
+		ChatID:    chat.ID,
+		UserID:    user.ID,
+		User:      user.String(),
+		IssuerID:  issuer.ID,
+		Issuer:    issuer.String(),
+		Reason:    reason,
+		MessageID: source.MessageID,
+		Link:      s.getSourceLink(source),
+		Date:      now.Unix(),
+	}
+	if expire := settings.GetWarnExpire(now); !expire.IsZero() {
+		w.Expire = expire.Unix()
+	}
+	if err = db.AddWarning(w); err != nil {
+		return fmt.Errorf("AddWarning: %s", err)
+	}
+
+	warnings, err := db.GetWarnings(chat.ID, user.ID)
+	if err != nil {
+		return fmt.Errorf("GetWarnings: %s", err)
+	}
+
+	lang := getLanguage(chat, nil)
+	policy := settings.GetWarnPolicy()
+	msgText := i18n.T(lang, "warn.issued", user.String(), w.ID, formatCounter(lang, len(warnings), policy))
+	if reason != "" {
+		msgText += i18n.T(lang, "reason", reason)
+	}
+	s.SendMessage(msgText, chat.ID, replyID)
+
+	step, until, ok, err := s.escalate(chat, user, len(warnings), policy, reason)
+	if err != nil {
+		return fmt.Errorf("escalate: %s", err)
+	}
+	if ok {
+		s.SendMessage(formatStep(lang, step, user, until, reason, db.GetChatLocation(chat.ID)), chat.ID, replyID)
+	}
+	return
+}
+
+// Warns command shows active warnings of user, own warnings are shown without target
+func (s *Server) Warns(msg *tgbotapi.Message) {
+	user := msg.From
+	if msg.ReplyToMessage != nil || strings.TrimSpace(msg.CommandArguments()) != "" {
+		var ok bool
+		if user, _, ok = s.getTarget(msg); !ok {
+			return
+		}
+	}
+
+	warnings, err := db.GetWarnings(msg.Chat.ID, user.ID)
+	if err != nil {
+		log.Printf("Error in Warns -> GetWarnings: %s", err)
+		return
+	}
+	lang := getMessageLanguage(msg)
+	if len(warnings) == 0 {
+		s.SendError(i18n.T(lang, "warns.empty", user.String()), msg)
+		return
+	}
+
+	loc := db.GetChatLocation(msg.Chat.ID)
+	lines := []string{i18n.T(lang, "warns.title", user.String())}
+	for _, w := range warnings {
+		lines = append(lines, formatWarning(lang, w, loc))
+	}
+	s.SendMessage(strings.Join(lines, "\n"), msg.Chat.ID, msg.MessageID)
+}
+
+// Unwarn command removes warning by number
+func (s *Server) Unwarn(msg *tgbotapi.Message) {
+	lang := getMessageLanguage(msg)
+	id, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(msg.CommandArguments()), "#"), 10, 64)
+	if err != nil {
+		s.SendError(i18n.T(lang, "unwarn.usage"), msg)
+		return
+	}
+
+	w, err := db.GetWarning(msg.Chat.ID, id)
+	if err != nil {
+		s.SendError(i18n.T(lang, "unwarn.not_found", id), msg)
+		return
+	}
+	if err = db.RemoveWarning(msg.Chat.ID, id); err != nil {
+		log.Printf("Error in Unwarn -> RemoveWarning: %s", err)
+		return
+	}
+	s.SendError(i18n.T(lang, "unwarn.removed", id, w.User), msg)
+}
+
+// WarnClear command removes all warnings of user in chat
+func (s *Server) WarnClear(msg *tgbotapi.Message) {
+	user, _, ok := s.getTarget(msg)
+	if !ok {
+		return
+	}
+
+	err := db.ClearWarnings(msg.Chat.ID, user.ID)
+	if err != nil {
+		log.Printf("Error in WarnClear -> ClearWarnings: %s", err)
+		return
+	}
+	s.SendError(i18n.T(getMessageLanguage(msg), "done"), msg)
+}
+
+// GetWarnLevel send message with current warning level for user
+func (s *Server) GetWarnLevel(msg *tgbotapi.Message) {
+	settings, err := db.GetChatSettings(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in GetWarnLevel -> GetChatSettings: %s", err)
+		return
+	}
+	warnings, err := db.GetWarnings(msg.Chat.ID, msg.From.ID)
+	if err != nil {
+		log.Printf("Error in GetWarnLevel -> GetWarnings: %s", err)
+		return
+	}
+	lang := getMessageLanguage(msg)
+	if len(warnings) == 0 {
+		s.SendError(i18n.T(lang, "mywarn.clean"), msg)
+		return
+	}
+	s.SendError(i18n.T(lang, "mywarn.level", formatCounter(lang, len(warnings), settings.GetWarnPolicy())), msg)
+}
+
+// WarnSettings command shows or changes warnings settings of chat
+func (s *Server) WarnSettings(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+
+	settings, err := db.GetChatSettings(msg.Chat.ID)
+	if err != nil {
+		log.Printf("Error in WarnSettings -> GetChatSettings: %s", err)
+		return
+	}
+
+	if len(args) > 0 {
+		if !s.checkRole(msg, RoleAdmin) {
+			return
+		}
+
+		var value int
+		if len(args) == 2 {
+			value, err = strconv.Atoi(args[1])
+		}
+		if len(args) != 2 || args[0] != "expire" || err != nil || value < 0 {
+			s.SendError(i18n.T(getMessageLanguage(msg), "warnset.usage"), msg)
+			return
+		}
+		settings.WarnExpireDays = value
+		if err = db.SaveChatSettings(settings); err != nil {
+			log.Printf("Error in WarnSettings -> SaveChatSettings: %s", err)
+			return
+		}
+	}
+
+	lang := getMessageLanguage(msg)
+	expire := i18n.T(lang, "warnset.forever")
+	if settings.WarnExpireDays > 0 {
+		expire = i18n.N(lang, "days", settings.WarnExpireDays, settings.WarnExpireDays)
+	}
+	s.SendMessage(i18n.T(lang, "warnset.description", expire, getPolicyDescription(lang, settings.GetWarnPolicy())), msg.Chat.ID, msg.MessageID)
+}
+
+// formatCounter returns counter value with ban level of policy
+func formatCounter(lang string, count int, policy escalation.Policy) string {
+	if banLevel := policy.BanLevel(); banLevel > 0 {
+		return i18n.T(lang, "counter.of", count, banLevel)
+	}
+	return fmt.Sprintf("%d", count)
+}
+
+// getSourceLink returns link to message in Telegram for public chats or in web archive
+func (s *Server) getSourceLink(msg *tgbotapi.Message) string {
+	if msg.Chat.UserName != "" {
+		return fmt.Sprintf("https://t.me/%s/%d", msg.Chat.UserName, msg.MessageID)
+	}
+	if s.BaseURL == "" {
+		return ""
+	}
+	return getMessageLink(strings.TrimRight(s.BaseURL, "/"), msg.Chat.ID, msg, db.GetChatLocation(msg.Chat.ID))
+}
+
+func formatWarning(lang string, w *db.Warning, loc *time.Location) string {
+	line := fmt.Sprintf("#%d %s", w.ID, time.Unix(w.Date, 0).In(loc).Format("02.01.2006 15:04"))
+	if w.Issuer != "" {
+		line += i18n.T(lang, "warns.issuer", w.Issuer)
+	}
+	if w.Reason != "" {
+		line += fmt.Sprintf(": %s", w.Reason)
+	}
+	if w.Expire != 0 {
+		line += i18n.T(lang, "warns.expire", time.Unix(w.Expire, 0).In(loc).Format("02.01.2006"))
+	}
+	if w.Link != "" {
+		line += " " + w.Link
+	}
+	return line
+}
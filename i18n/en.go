@@ -0,0 +1,339 @@
+package i18n
+
+// en is a catalog of English messages
+var en = map[string]string{
+	"action.ban":             "ban",
+	"action.delete_message":  "deleting message",
+	"action.delete_messages": "deleting messages",
+	"action.mute":            "mute for %s",
+	"action.tempban":         "ban for %s",
+	"action.warn":            "warning",
+
+	"archive.description": "Chat web archive is %s, feed mode: %s",
+	"archive.hidden":      "hidden",
+	"archive.shown":       "public",
+	"archive.usage":       "Usage: /archive [hide|show|feed message|feed day]",
+
+	"ban.failed": "Failed to ban/unban user: %s",
+
+	"banlist.empty": "Hooray! We are clean! Nobody is banned",
+	"banlist.title": "Banned users:\n%s",
+
+	"captcha.button":            "I am not a robot",
+	"captcha.button_question":   "%s, welcome! Press the button within %d min. to write in the chat.",
+	"captcha.check_failed":      "Failed to check answer, please try again",
+	"captcha.description":       "New members check is %s\nMode: %s\nTime to answer: %d min.",
+	"captcha.failed":            "User %s failed the check (%s) and was removed from the chat",
+	"captcha.finished":          "Check is already finished",
+	"captcha.math_question":     "%s, welcome! What is %d + %d? Answer within %d min. to write in the chat.",
+	"captcha.mode.button":       "button",
+	"captcha.mode.math":         "math",
+	"captcha.not_yours":         "This check is not for you",
+	"captcha.off":               "disabled",
+	"captcha.on":                "enabled",
+	"captcha.passed":            "Check passed, welcome!",
+	"captcha.timeout_reason":    "time is out",
+	"captcha.unrestrict_failed": "Failed to lift restrictions, please contact administrators",
+	"captcha.usage":             "Usage: /captcha [on|off|mode button|math|timeout <minutes>]",
+	"captcha.wrong":             "Wrong answer",
+	"captcha.wrong_reason":      "wrong answer",
+
+	"cens.action.count":   "count only",
+	"cens.action.delete":  "delete and count",
+	"cens.action.reply":   "reply and count",
+	"cens.banned":         "Congratulations, %s! You have exceeded swearing limit for the year and leave the chat!",
+	"cens.description":    "Swearing check is %s\nAction: %s\nDictionaries: %s\nNot checked: %s",
+	"cens.dict_not_found": "Dictionary %s not found, available dictionaries: %s",
+	"cens.exempt_role":    "«%s» and higher",
+	"cens.off":            "disabled",
+	"cens.on":             "enabled",
+	"cens.reason":         "swearing",
+	"cens.removed":        "Message of %s deleted: swearing",
+	"cens.scold":          "Stop swearing, %s! This is not a flea market!",
+	"cens.usage":          "Usage: /cens [on|off|action reply|delete|count|exempt trusted|moderator|admin|none|dict <dictionary>...|dict default]",
+
+	"censlist.dictionaries": "Chat dictionaries: %s\nAvailable dictionaries: %s",
+	"censlist.no_words":     "\nNo chat words",
+	"censlist.words":        "\nChat words: %s",
+
+	"censword.added":     "Added to chat dictionary: %s",
+	"censword.invalid":   "Invalid word: %s",
+	"censword.removed":   "Removed from chat dictionary: %s",
+	"censword.unchanged": "Chat dictionary is not changed",
+	"censword.usage":     "Usage: /censword add|remove <word>..., word may be a pattern with * and ?, a regular expression /.../ or an allowed word !word",
+
+	"cmd.archive.args":    "[hide|show|feed message|feed day]",
+	"cmd.archive.desc":    "show or change (for administrators) web archive settings of this chat",
+	"cmd.ban.args":        "@username|name|ID (or reply to a message)",
+	"cmd.ban.desc":        "ban user in the group (the bot must be a group administrator)",
+	"cmd.banlist.desc":    "show banned users",
+	"cmd.captcha.args":    "[on|off|mode button|math|timeout <minutes>]",
+	"cmd.captcha.desc":    "show or change (for administrators) check of new members",
+	"cmd.cens.args":       "[on|off|action <action>|exempt <role>|dict <dictionary>...]",
+	"cmd.cens.desc":       "show or change (for administrators) swearing check settings of this chat",
+	"cmd.censlist.desc":   "show swearing dictionaries and words of this chat",
+	"cmd.censword.args":   "add|remove <word>...",
+	"cmd.censword.desc":   "add words to swearing dictionary of this chat or remove them",
+	"cmd.clearcens.args":  "@username|name|ID (or reply to a message)",
+	"cmd.clearcens.desc":  "clear swearing counter of user in this chat",
+	"cmd.clearwarn.args":  "@username|name|ID (or reply to a message)",
+	"cmd.clearwarn.desc":  "clear warnings of user in this chat",
+	"cmd.demote.args":     "@username|name|ID (or reply to a message)",
+	"cmd.demote.desc":     "remove delegated role from user",
+	"cmd.flood.args":      "[on|off|limit <messages> <seconds>|action <action>...|mute <duration>]",
+	"cmd.flood.desc":      "show or change (for administrators) flood protection settings of this chat",
+	"cmd.help.desc":       "help on bot commands",
+	"cmd.lang.args":       "[ru|en|default]",
+	"cmd.lang.desc":       "show or change (for administrators) bot language in this chat",
+	"cmd.mute.args":       "@username|name|ID (or reply to a message) [duration] [reason]",
+	"cmd.mute.desc":       "forbid user to write, for an hour by default",
+	"cmd.mycens.desc":     "show your own swearing counter in this chat",
+	"cmd.mywarn.desc":     "show your own warnings counter in this chat",
+	"cmd.ping.desc":       "joke ping",
+	"cmd.policy.args":     "[warn|cens] [set <level:action[:duration]>...|reset]",
+	"cmd.policy.desc":     "show or change (for administrators) sanctions for warnings and swearing",
+	"cmd.promote.args":    "@username|name|ID (or reply to a message) [moderator|trusted]",
+	"cmd.promote.desc":    "delegate moderator or trusted member role to user",
+	"cmd.readonly.args":   "@username|name|ID (or reply to a message) [duration] [reason]",
+	"cmd.readonly.desc":   "leave user read-only access, forever by default",
+	"cmd.report.args":     "[reason] (in reply to message)",
+	"cmd.report.desc":     "report message to administrators",
+	"cmd.reportchat.args": "[<chat ID>|private]",
+	"cmd.reportchat.desc": "show or set (for administrators) chat for reports of members",
+	"cmd.roles.desc":      "show delegated roles in this chat",
+	"cmd.setgoodbye.args": "[off|delete <minutes>|[markdown|html] <text>]",
+	"cmd.setgoodbye.desc": "show or change farewell to left members",
+	"cmd.setwelcome.args": "[off|delete <minutes>|[markdown|html] <text>]",
+	"cmd.setwelcome.desc": "show or change greeting of new members",
+	"cmd.spam.args":       "[on|off|new <messages> <hours>|block <kind>...|action <action>...|mute <duration>]",
+	"cmd.spam.desc":       "show or change (for administrators) spam filter settings for new members",
+	"cmd.spamlog.args":    "[count]",
+	"cmd.spamlog.desc":    "show the last messages blocked by spam filter",
+	"cmd.start.desc":      "greeting (standard for any Telegram bot)",
+	"cmd.tempban.args":    "@username|name|ID (or reply to a message) <duration> [reason]",
+	"cmd.tempban.desc":    "ban user for a while, e.g. 2d",
+	"cmd.timezone.args":   "[Europe/Moscow|default]",
+	"cmd.timezone.desc":   "show or set (for administrators) chat timezone",
+	"cmd.unban.args":      "@username|name|ID (or reply to a message)",
+	"cmd.unban.desc":      "unban user in the group (the bot must be a group administrator)",
+	"cmd.unmute.args":     "@username|name|ID (or reply to a message)",
+	"cmd.unmute.desc":     "allow user to write again after mute or read-only mode",
+	"cmd.unwarn.args":     "<number>",
+	"cmd.unwarn.desc":     "remove warning by number",
+	"cmd.warn.args":       "@username|name|ID (or reply to a message) [reason]",
+	"cmd.warn.desc":       "warn user, sanctions for warnings are set by /policy command",
+	"cmd.warns.args":      "[@username|name|ID (or reply to a message)]",
+	"cmd.warns.desc":      "show warnings of user in this chat",
+	"cmd.warnset.args":    "[expire <days>]",
+	"cmd.warnset.desc":    "show or change (for administrators) expiration of warnings",
+
+	"command.role_required": "Command is available for role «%s» and higher",
+	"command.unavailable":   "Command /%s is not available in this chat",
+
+	"counter.of": "%d of %d",
+
+	"days.one":   "%d day",
+	"days.other": "%d days",
+
+	"done": "Done.",
+
+	"duration.invalid": "Invalid duration %s, examples: 30m, 2h, 1d, 1w",
+
+	"flood.description": "Flood protection is %s\nLimit: %s in %s\nActions: %s",
+	"flood.off":         "disabled",
+	"flood.on":          "enabled",
+	"flood.reason":      "flood",
+	"flood.removed":     "Deleted messages of user %s: %d",
+	"flood.usage":       "Usage: /flood [on|off|limit <messages> <seconds>|action warn|mute|delete...|mute <duration>]",
+
+	"greeting.delete_after": "deleted after %d min.",
+	"greeting.description":  "Template (%s, %s):\n%s",
+	"greeting.invalid":      "Failed to send message, check markup: %s",
+	"greeting.kept":         "not deleted",
+	"greeting.off":          "Message is disabled",
+	"greeting.plain":        "plain text",
+	"greeting.usage":        "Usage: /%s [off|delete <minutes>|[markdown|html] <text>], text may contain {name}, {mention}, {chat} and {count}",
+
+	"help.aliases": " (also /%s)",
+	"help.title":   "Bot commands help.",
+
+	"hours.one":   "%d hour",
+	"hours.other": "%d hours",
+
+	"lang.current": "Bot language: %s, available languages: %s",
+	"lang.set":     "Bot language: %s",
+	"lang.unknown": "Language %s is not supported, available languages: %s",
+
+	"messages.one":   "%d message",
+	"messages.other": "%d messages",
+
+	"month.1":  "January",
+	"month.2":  "February",
+	"month.3":  "March",
+	"month.4":  "April",
+	"month.5":  "May",
+	"month.6":  "June",
+	"month.7":  "July",
+	"month.8":  "August",
+	"month.9":  "September",
+	"month.10": "October",
+	"month.11": "November",
+	"month.12": "December",
+
+	"mycens.clean": "You are pure in heart!",
+	"mycens.level": "Your personal swearing counter: %d",
+
+	"mywarn.clean": "Pure in heart!",
+	"mywarn.level": "Alert level: %s",
+
+	"none": "none",
+
+	"ping.reply": "%s ping from you %3.3f",
+
+	"policy.counter":     "Policy %s: %s (%s)",
+	"policy.description": "Warnings: %s\nSwearing: %s",
+	"policy.empty":       "no sanctions",
+	"policy.invalid":     "Invalid policy: %s\n%s",
+	"policy.usage":       "Usage: /policy [warn|cens] [set <level:action[:duration]>...|reset], e.g.: /policy warn set 3:warn 5:mute:1h 8:ban",
+	"policy.warning":     "%s, be careful! Next violations will lead to sanctions.",
+
+	"reason": ". Reason: %s",
+
+	"report.button.ban":       "Ban",
+	"report.button.dismiss":   "Dismiss",
+	"report.button.mute":      "Mute",
+	"report.button.warn":      "Warn",
+	"report.chat_unavailable": "Chat of the report is unavailable to the bot",
+	"report.default_reason":   "report of member",
+	"report.duplicate":        "This message is already reported, administrators will handle it",
+	"report.failed":           "Failed to send report to administrators",
+	"report.handled":          "Handled by %s: %s",
+	"report.not_found":        "Report not found",
+	"report.reason_line":      "Reason: %s",
+	"report.reporter":         "From: %s",
+	"report.self":             "You can't report your own messages!",
+	"report.sent":             "Report is sent to administrators",
+	"report.status.ban":       "banned",
+	"report.status.dismiss":   "dismissed",
+	"report.status.mute":      "muted",
+	"report.status.warn":      "warned",
+	"report.text":             "Message: %s",
+	"report.title":            "Report in chat %s",
+	"report.usage":            "Reply with /report [reason] to the message you want to report to administrators",
+	"report.user":             "User: %s",
+
+	"reportchat.chat":    "Reports are sent to chat %d",
+	"reportchat.failed":  "Failed to write to chat %d, add the bot to this chat",
+	"reportchat.linked":  "Reports of members of chat %s will be sent here",
+	"reportchat.private": "Reports are sent to administrators and moderators privately",
+	"reportchat.usage":   "Usage: /reportchat [<chat ID>|private]",
+
+	"restrict.banned":            "User %s is banned",
+	"restrict.duration_required": "Specify duration, e.g.: /%s %s 1d",
+	"restrict.failed":            "Failed to restrict user %s: %s",
+	"restrict.forever":           " forever",
+	"restrict.lift_failed":       "Failed to lift restrictions of user %s: %s",
+	"restrict.lifted":            "User %s can write again",
+	"restrict.muted":             "User %s can't write",
+	"restrict.readonly":          "User %s can only read the chat",
+	"restrict.until":             " until %s",
+
+	"role.admin":            "administrator",
+	"role.member":           "member",
+	"role.moderator":        "moderator",
+	"role.own_unknown":      "Failed to get your rights in this chat!",
+	"role.owner":            "owner",
+	"role.target_protected": "Command is not applicable to user %s with role «%s»",
+	"role.trusted":          "trusted",
+
+	"roles.demoted":       "User %s is an ordinary member now",
+	"roles.empty":         "No roles are delegated in this chat",
+	"roles.promote_usage": "Usage: /promote %s [moderator|trusted]",
+	"roles.promoted":      "User %s got role «%s»",
+	"roles.title":         "Delegated roles:",
+
+	"seconds.one":   "%d second",
+	"seconds.other": "%d seconds",
+
+	"spam.description":  "Spam filter is %s\nMembers are new until they write %s and spend %s in chat\nBlocked: %s\nActions: %s",
+	"spam.kind.forward": "channel repost",
+	"spam.kind.invite":  "Telegram link",
+	"spam.kind.link":    "link",
+	"spam.kind.media":   "media",
+	"spam.off":          "disabled",
+	"spam.on":           "enabled",
+	"spam.reason":       "spam (%s)",
+	"spam.removed":      "Message of new member %s deleted",
+	"spam.usage":        "Usage: /spam [on|off|new <messages> <hours>|block link|invite|forward|media...|action delete|warn|mute|ban...|mute <duration>]",
+
+	"spamlog.empty": "Spam filter has blocked nothing",
+	"spamlog.entry": "#%d %s %s: %s «%s» (actions: %s)",
+	"spamlog.title": "Blocked by spam filter:",
+	"spamlog.usage": "Usage: /spamlog [number of entries]",
+
+	"start.hello": "Hello,",
+
+	"target.error":     "Unknown error while searching for user: %s",
+	"target.many":      "More than one user found, please specify:\n%s",
+	"target.not_found": "User %s not found",
+	"target.self":      "You can't do that to me!",
+	"target.usage":     "Specify user: reply to their message, mention them or give @username, name or ID",
+
+	"timezone.current": "Chat timezone: %s",
+	"timezone.failed":  "Failed to set timezone %s",
+
+	"unwarn.not_found": "Warning #%d not found",
+	"unwarn.removed":   "Warning #%d of user %s removed",
+	"unwarn.usage":     "Usage: /unwarn <warning number>",
+
+	"warn.issued": "User %s got warning #%d (%s)",
+	"warn.self":   "Yourself? O_o",
+
+	"warns.empty":  "User %s has no warnings",
+	"warns.expire": " (until %s)",
+	"warns.issuer": " by %s",
+	"warns.title":  "Warnings of user %s:",
+
+	"warnset.description": "Warning expires: %s, sanctions: %s",
+	"warnset.forever":     "never",
+	"warnset.usage":       "Usage: /warnset [expire <days>], sanctions are set by /policy warn command",
+
+	"web.admin_hint":           "Archive admins see messages removed by moderation. Empty token logs out.",
+	"web.admin_login":          "Log in",
+	"web.admin_token":          "Admin token",
+	"web.audio":                "Audio in message",
+	"web.calendar":             "Calendar",
+	"web.chat_not_found":       "Chat not found",
+	"web.chats":                "Chats",
+	"web.document":             "Document in message",
+	"web.feed_description":     "Telegram logs of %s",
+	"web.first_page":           "First page",
+	"web.latest":               "Latest activity",
+	"web.message":              "Message",
+	"web.messages":             "Messages",
+	"web.messages_unavailable": "Messages are unavailable",
+	"web.month":                "%s %d",
+	"web.months":               "Months",
+	"web.next_page":            "Next page",
+	"web.photo":                "Photo in message",
+	"web.removed":              "Removed by moderation: %s",
+	"web.reply_hidden":         "Message removed by moderation",
+	"web.reset":                "reset",
+	"web.settings_unavailable": "Chat settings are unavailable",
+	"web.sticker":              "Sticker in message",
+	"web.timezone":             "Timezone: %s",
+	"web.title":                "Telegram logs",
+	"web.today":                "Today",
+	"web.video":                "Video in message",
+	"web.voice":                "Voice in message",
+	"web.wrong_month":          "Wrong month %d",
+	"web.years":                "Years",
+
+	"weekday.0": "Sun",
+	"weekday.1": "Mon",
+	"weekday.2": "Tue",
+	"weekday.3": "Wed",
+	"weekday.4": "Thu",
+	"weekday.5": "Fri",
+	"weekday.6": "Sat",
+}
@@ -0,0 +1,166 @@
+// Package i18n is a catalog of bot and web archive messages in bundled languages with plural forms,
+// it doesn't depend on Telegram and database
+package i18n
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLanguage is used if message has no translation to requested language
+const DefaultLanguage = "ru"
+
+// Plural forms of CLDR plural rules
+const (
+	PluralOne   = "one"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+// catalogs of messages by language, messages are fmt formats
+var catalogs = map[string]map[string]string{
+	"ru": ru,
+	"en": en,
+}
+
+// pluralRules returns plural form of number by language
+var pluralRules = map[string]func(n int) string{
+	"ru": func(n int) string {
+		if n < 0 {
+			n = -n
+		}
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return PluralOne
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return PluralFew
+		}
+		return PluralMany
+	},
+	"en": func(n int) string {
+		if n == 1 || n == -1 {
+			return PluralOne
+		}
+		return PluralOther
+	},
+}
+
+// Languages returns sorted codes of supported languages
+func Languages() (langs []string) {
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return
+}
+
+// Match returns supported language for language tag like en-US, ok is false if language isn't supported
+func Match(tag string) (lang string, ok bool) {
+	lang = strings.ToLower(tag)
+	if i := strings.IndexAny(lang, "-_"); i != -1 {
+		lang = lang[:i]
+	}
+	_, ok = catalogs[lang]
+	return
+}
+
+// MatchAcceptLanguage returns the most preferred supported language of Accept-Language header,
+// ok is false if header has no supported language
+func MatchAcceptLanguage(header string) (lang string, ok bool) {
+	best := 0.0
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q <= best {
+			continue
+		}
+		if l, found := Match(strings.TrimSpace(params[0])); found {
+			lang, ok, best = l, true, q
+		}
+	}
+	return
+}
+
+// MonthName returns name of month in language
+func MonthName(lang string, month time.Month) string {
+	return T(lang, fmt.Sprintf("month.%d", month))
+}
+
+// WeekdayName returns short name of weekday in language
+func WeekdayName(lang string, day time.Weekday) string {
+	return T(lang, fmt.Sprintf("weekday.%d", day))
+}
+
+// T returns message by key in language formatted with args, message in default language is used
+// if language has no message and key is returned if no language has it
+func T(lang, key string, args ...interface{}) string {
+	format, ok := catalogs[lang][key]
+	if !ok {
+		if format, ok = catalogs[DefaultLanguage][key]; !ok {
+			log.Printf("Message %s not found in catalog", key)
+			format = key
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// N returns message by key in plural form for number n formatted with args,
+// plural forms are stored with keys key.one, key.few, key.many and key.other
+func N(lang, key string, n int, args ...interface{}) string {
+	if _, ok := catalogs[lang]; !ok {
+		lang = DefaultLanguage
+	}
+	form := PluralOther
+	if rule, ok := pluralRules[lang]; ok {
+		form = rule(n)
+	}
+	if _, ok := catalogs[lang][key+"."+form]; !ok {
+		form = PluralOther
+	}
+	return T(lang, key+"."+form, args...)
+}
+
+// Error is an error with catalog message, it is translated to language of chat where it is shown
+type Error struct {
+	Key  string
+	Args []interface{}
+}
+
+// Errorf returns error with catalog message by key formatted with args
+func Errorf(key string, args ...interface{}) *Error {
+	return &Error{Key: key, Args: args}
+}
+
+// Error returns message in default language
+func (e *Error) Error() string {
+	return e.Text(DefaultLanguage)
+}
+
+// Text returns message in language
+func (e *Error) Text(lang string) string {
+	return T(lang, e.Key, e.Args...)
+}
+
+// ErrorText returns message of err in language if err has catalog message
+func ErrorText(lang string, err error) string {
+	if e, ok := err.(*Error); ok {
+		return e.Text(lang)
+	}
+	return err.Error()
+}
@@ -0,0 +1,347 @@
+package i18n
+
+// ru is a catalog of Russian messages, it is the default language and must have every message
+var ru = map[string]string{
+	"action.ban":             "бан",
+	"action.delete_message":  "удаление сообщения",
+	"action.delete_messages": "удаление сообщений",
+	"action.mute":            "мут на %s",
+	"action.tempban":         "бан на %s",
+	"action.warn":            "предупреждение",
+
+	"archive.description": "Веб-архив чата %s, режим ленты: %s",
+	"archive.hidden":      "скрыт",
+	"archive.shown":       "открыт",
+	"archive.usage":       "Использование: /archive [hide|show|feed message|feed day]",
+
+	"ban.failed": "Не удалось забанить/разбанить пользователя: %s",
+
+	"banlist.empty": "Ура! Мы чисты! Забаненых нет",
+	"banlist.title": "Список забанненных лиц:\n%s",
+
+	"captcha.button":            "Я не робот",
+	"captcha.button_question":   "%s, добро пожаловать! Нажмите кнопку в течение %d мин., чтобы писать в чат.",
+	"captcha.check_failed":      "Не удалось проверить ответ, попробуйте еще раз",
+	"captcha.description":       "Проверка новых участников %s\nРежим: %s\nВремя на ответ: %d мин.",
+	"captcha.failed":            "Пользователь %s не прошел проверку (%s) и удален из чата",
+	"captcha.finished":          "Проверка уже завершена",
+	"captcha.math_question":     "%s, добро пожаловать! Сколько будет %d + %d? Ответьте в течение %d мин., чтобы писать в чат.",
+	"captcha.mode.button":       "кнопка",
+	"captcha.mode.math":         "пример",
+	"captcha.not_yours":         "Эта проверка не для Вас",
+	"captcha.off":               "выключена",
+	"captcha.on":                "включена",
+	"captcha.passed":            "Проверка пройдена, добро пожаловать!",
+	"captcha.timeout_reason":    "время истекло",
+	"captcha.unrestrict_failed": "Не удалось снять ограничения, обратитесь к администраторам",
+	"captcha.usage":             "Использование: /captcha [on|off|mode button|math|timeout <минут>]",
+	"captcha.wrong":             "Неверный ответ",
+	"captcha.wrong_reason":      "неверный ответ",
+
+	"cens.action.count":   "только подсчет",
+	"cens.action.delete":  "удаление и подсчет",
+	"cens.action.reply":   "ответ и подсчет",
+	"cens.banned":         "Поздравляю, %s! Вы превысили количество бранных слов в году и выбываете из чата!",
+	"cens.description":    "Проверка брани %s\nДействие: %s\nСловари: %s\nНе проверяются: %s",
+	"cens.dict_not_found": "Словарь %s не найден, доступные словари: %s",
+	"cens.exempt_role":    "«%s» и выше",
+	"cens.off":            "выключена",
+	"cens.on":             "включена",
+	"cens.reason":         "брань",
+	"cens.removed":        "Сообщение %s удалено: брань",
+	"cens.scold":          "Перестаньте сказать, %s! Вы не на привозе!",
+	"cens.usage":          "Использование: /cens [on|off|action reply|delete|count|exempt trusted|moderator|admin|none|dict <словарь>...|dict default]",
+
+	"censlist.dictionaries": "Словари чата: %s\nДоступные словари: %s",
+	"censlist.no_words":     "\nСлов чата нет",
+	"censlist.words":        "\nСлова чата: %s",
+
+	"censword.added":     "Добавлено в словарь чата: %s",
+	"censword.invalid":   "Неверное слово: %s",
+	"censword.removed":   "Удалено из словаря чата: %s",
+	"censword.unchanged": "Словарь чата не изменился",
+	"censword.usage":     "Использование: /censword add|remove <слово>..., слово может быть шаблоном со * и ?, регулярным выражением /.../ или разрешенным словом !слово",
+
+	"cmd.archive.args":    "[hide|show|feed message|feed day]",
+	"cmd.archive.desc":    "показать или изменить (администраторам) настройки веб-архива чата",
+	"cmd.ban.args":        "@username|имя|ID (или ответом на сообщение)",
+	"cmd.ban.desc":        "забанить пользователя в группе (бот должен иметь административные права в группе)",
+	"cmd.banlist.desc":    "показать список забаненых пользователей",
+	"cmd.captcha.args":    "[on|off|mode button|math|timeout <минут>]",
+	"cmd.captcha.desc":    "показать или изменить (администраторам) проверку новых участников",
+	"cmd.cens.args":       "[on|off|action <действие>|exempt <роль>|dict <словарь>...]",
+	"cmd.cens.desc":       "показать или изменить (администраторам) настройки проверки брани в этом чате",
+	"cmd.censlist.desc":   "показать словари брани и слова этого чата",
+	"cmd.censword.args":   "add|remove <слово>...",
+	"cmd.censword.desc":   "добавить слова в словарь брани этого чата или удалить их",
+	"cmd.clearcens.args":  "@username|имя|ID (или ответом на сообщение)",
+	"cmd.clearcens.desc":  "очистить счетчик бранных слов пользователя в этом чате",
+	"cmd.clearwarn.args":  "@username|имя|ID (или ответом на сообщение)",
+	"cmd.clearwarn.desc":  "очистить счетчик предупреждений пользователя в этом чате",
+	"cmd.demote.args":     "@username|имя|ID (или ответом на сообщение)",
+	"cmd.demote.desc":     "снять с пользователя назначенную роль",
+	"cmd.flood.args":      "[on|off|limit <сообщений> <секунд>|action <действие>...|mute <длительность>]",
+	"cmd.flood.desc":      "показать или изменить (администраторам) настройки защиты от флуда в этом чате",
+	"cmd.help.desc":       "помощь по командам бота",
+	"cmd.lang.args":       "[ru|en|default]",
+	"cmd.lang.desc":       "показать или сменить (для администраторов) язык бота в этом чате",
+	"cmd.mute.args":       "@username|имя|ID (или ответом на сообщение) [длительность] [причина]",
+	"cmd.mute.desc":       "запретить пользователю писать, по умолчанию на час",
+	"cmd.mycens.desc":     "показать собственный счетчик бранных слов в этом чате",
+	"cmd.mywarn.desc":     "показать собственный счетчик предупреждений в этом чате",
+	"cmd.ping.desc":       "шуточный пинг",
+	"cmd.policy.args":     "[warn|cens] [set <уровень:действие[:длительность]>...|reset]",
+	"cmd.policy.desc":     "показать или изменить (администраторам) санкции за предупреждения и брань",
+	"cmd.promote.args":    "@username|имя|ID (или ответом на сообщение) [moderator|trusted]",
+	"cmd.promote.desc":    "назначить пользователю роль модератора или доверенного участника",
+	"cmd.readonly.args":   "@username|имя|ID (или ответом на сообщение) [длительность] [причина]",
+	"cmd.readonly.desc":   "оставить пользователю только чтение, по умолчанию бессрочно",
+	"cmd.report.args":     "[причина] (ответом на сообщение)",
+	"cmd.report.desc":     "пожаловаться администраторам на сообщение",
+	"cmd.reportchat.args": "[<ID чата>|private]",
+	"cmd.reportchat.desc": "показать или установить (администраторам) чат для жалоб участников",
+	"cmd.roles.desc":      "показать назначенные роли в этом чате",
+	"cmd.setgoodbye.args": "[off|delete <минут>|[markdown|html] <текст>]",
+	"cmd.setgoodbye.desc": "показать или изменить прощание с ушедшими участниками",
+	"cmd.setwelcome.args": "[off|delete <минут>|[markdown|html] <текст>]",
+	"cmd.setwelcome.desc": "показать или изменить приветствие новых участников",
+	"cmd.spam.args":       "[on|off|new <сообщений> <часов>|block <тип>...|action <действие>...|mute <длительность>]",
+	"cmd.spam.desc":       "показать или изменить (администраторам) настройки спам-фильтра для новых участников",
+	"cmd.spamlog.args":    "[количество]",
+	"cmd.spamlog.desc":    "показать последние сообщения, заблокированные спам-фильтром",
+	"cmd.start.desc":      "приветствие (стандартная для любого бота Telegram)",
+	"cmd.tempban.args":    "@username|имя|ID (или ответом на сообщение) <длительность> [причина]",
+	"cmd.tempban.desc":    "забанить пользователя на время, например 2d",
+	"cmd.timezone.args":   "[Europe/Moscow|default]",
+	"cmd.timezone.desc":   "показать или установить (администраторам) часовой пояс чата",
+	"cmd.unban.args":      "@username|имя|ID (или ответом на сообщение)",
+	"cmd.unban.desc":      "разбанить пользователя в группе (бот должен иметь административные права в группе)",
+	"cmd.unmute.args":     "@username|имя|ID (или ответом на сообщение)",
+	"cmd.unmute.desc":     "снять с пользователя запрет писать или режим только чтения",
+	"cmd.unwarn.args":     "<номер>",
+	"cmd.unwarn.desc":     "снять предупреждение по номеру",
+	"cmd.warn.args":       "@username|имя|ID (или ответом на сообщение) [причина]",
+	"cmd.warn.desc":       "предупредить пользователя, санкции за предупреждения настраиваются командой /policy",
+	"cmd.warns.args":      "[@username|имя|ID (или ответом на сообщение)]",
+	"cmd.warns.desc":      "показать предупреждения пользователя в этом чате",
+	"cmd.warnset.args":    "[expire <дней>]",
+	"cmd.warnset.desc":    "показать или изменить (администраторам) срок действия предупреждений",
+
+	"command.role_required": "Команда доступна с ролью «%s» и выше",
+	"command.unavailable":   "Команда /%s недоступна в этом чате",
+
+	"counter.of": "%d из %d",
+
+	"days.few":   "%d дня",
+	"days.many":  "%d дней",
+	"days.one":   "%d день",
+	"days.other": "%d дней",
+
+	"done": "Выполнено успешно.",
+
+	"duration.invalid": "Неверная длительность %s, примеры: 30m, 2h, 1d, 1w",
+
+	"flood.description": "Защита от флуда %s\nЛимит: %s за %s\nДействия: %s",
+	"flood.off":         "выключена",
+	"flood.on":          "включена",
+	"flood.reason":      "флуд",
+	"flood.removed":     "Удалено сообщений пользователя %s: %d",
+	"flood.usage":       "Использование: /flood [on|off|limit <сообщений> <секунд>|action warn|mute|delete...|mute <длительность>]",
+
+	"greeting.delete_after": "удаляется через %d мин.",
+	"greeting.description":  "Шаблон (%s, %s):\n%s",
+	"greeting.invalid":      "Не удалось отправить сообщение, проверьте разметку: %s",
+	"greeting.kept":         "не удаляется",
+	"greeting.off":          "Сообщение отключено",
+	"greeting.plain":        "обычный текст",
+	"greeting.usage":        "Использование: /%s [off|delete <минут>|[markdown|html] <текст>], в тексте можно использовать {name}, {mention}, {chat} и {count}",
+
+	"help.aliases": " (также /%s)",
+	"help.title":   "Помощь по командам бота.",
+
+	"hours.few":   "%d часа",
+	"hours.many":  "%d часов",
+	"hours.one":   "%d час",
+	"hours.other": "%d часов",
+
+	"lang.current": "Язык бота: %s, доступные языки: %s",
+	"lang.set":     "Язык бота: %s",
+	"lang.unknown": "Язык %s не поддерживается, доступные языки: %s",
+
+	"messages.few":   "%d сообщения",
+	"messages.many":  "%d сообщений",
+	"messages.one":   "%d сообщение",
+	"messages.other": "%d сообщений",
+
+	"month.1":  "Январь",
+	"month.2":  "Февраль",
+	"month.3":  "Март",
+	"month.4":  "Апрель",
+	"month.5":  "Май",
+	"month.6":  "Июнь",
+	"month.7":  "Июль",
+	"month.8":  "Август",
+	"month.9":  "Сентябрь",
+	"month.10": "Октябрь",
+	"month.11": "Ноябрь",
+	"month.12": "Декабрь",
+
+	"mycens.clean": "Ты чист душой!",
+	"mycens.level": "Твой личный счетчик бранных слов: %d",
+
+	"mywarn.clean": "Чист душой!",
+	"mywarn.level": "Уровень настороженности: %s",
+
+	"none": "нет",
+
+	"ping.reply": "%s пинг от тебя %3.3f",
+
+	"policy.counter":     "Политика %s: %s (%s)",
+	"policy.description": "Предупреждения: %s\nБрань: %s",
+	"policy.empty":       "без санкций",
+	"policy.invalid":     "Неверная политика: %s\n%s",
+	"policy.usage":       "Использование: /policy [warn|cens] [set <уровень:действие[:длительность]>...|reset], например: /policy warn set 3:warn 5:mute:1h 8:ban",
+	"policy.warning":     "%s, будьте осторожны! Следующие нарушения приведут к санкциям.",
+
+	"reason": ". Причина: %s",
+
+	"report.button.ban":       "Забанить",
+	"report.button.dismiss":   "Отклонить",
+	"report.button.mute":      "Запретить писать",
+	"report.button.warn":      "Предупредить",
+	"report.chat_unavailable": "Чат жалобы недоступен боту",
+	"report.default_reason":   "жалоба участника",
+	"report.duplicate":        "На это сообщение уже пожаловались, администраторы разберутся",
+	"report.failed":           "Не удалось отправить жалобу администраторам",
+	"report.handled":          "Обработал %s: %s",
+	"report.not_found":        "Жалоба не найдена",
+	"report.reason_line":      "Причина: %s",
+	"report.reporter":         "От: %s",
+	"report.self":             "На свои сообщения жаловаться нельзя!",
+	"report.sent":             "Жалоба отправлена администраторам",
+	"report.status.ban":       "бан",
+	"report.status.dismiss":   "отклонено",
+	"report.status.mute":      "запрет писать",
+	"report.status.warn":      "предупреждение",
+	"report.text":             "Сообщение: %s",
+	"report.title":            "Жалоба в чате %s",
+	"report.usage":            "Ответьте командой /report [причина] на сообщение, о котором нужно сообщить администраторам",
+	"report.user":             "На: %s",
+
+	"reportchat.chat":    "Жалобы отправляются в чат %d",
+	"reportchat.failed":  "Не удалось написать в чат %d, добавьте бота в этот чат",
+	"reportchat.linked":  "Сюда будут приходить жалобы участников чата %s",
+	"reportchat.private": "Жалобы отправляются администраторам и модераторам в личные сообщения",
+	"reportchat.usage":   "Использование: /reportchat [<ID чата>|private]",
+
+	"restrict.banned":            "Пользователь %s забанен",
+	"restrict.duration_required": "Укажите длительность, например: /%s %s 1d",
+	"restrict.failed":            "Не удалось ограничить пользователя %s: %s",
+	"restrict.forever":           " бессрочно",
+	"restrict.lift_failed":       "Не удалось снять ограничения с пользователя %s: %s",
+	"restrict.lifted":            "Пользователь %s снова может писать",
+	"restrict.muted":             "Пользователь %s не может писать",
+	"restrict.readonly":          "Пользователь %s может только читать чат",
+	"restrict.until":             " до %s",
+
+	"role.admin":            "администратор",
+	"role.member":           "участник",
+	"role.moderator":        "модератор",
+	"role.own_unknown":      "Не удалось установить Ваши права в этом чате!",
+	"role.owner":            "владелец",
+	"role.target_protected": "Команда неприменима к пользователю %s с ролью «%s»",
+	"role.trusted":          "доверенный",
+
+	"roles.demoted":       "Пользователь %s теперь обычный участник",
+	"roles.empty":         "В этом чате нет назначенных ролей",
+	"roles.promote_usage": "Использование: /promote %s [moderator|trusted]",
+	"roles.promoted":      "Пользователь %s получил роль «%s»",
+	"roles.title":         "Назначенные роли:",
+
+	"seconds.few":   "%d секунды",
+	"seconds.many":  "%d секунд",
+	"seconds.one":   "%d секунду",
+	"seconds.other": "%d секунд",
+
+	"spam.description":  "Спам-фильтр %s\nУчастник считается новым, пока не напишет %s и не проведет в чате %s\nБлокируется: %s\nДействия: %s",
+	"spam.kind.forward": "репост из канала",
+	"spam.kind.invite":  "ссылка на Telegram",
+	"spam.kind.link":    "ссылка",
+	"spam.kind.media":   "медиа",
+	"spam.off":          "выключен",
+	"spam.on":           "включен",
+	"spam.reason":       "спам (%s)",
+	"spam.removed":      "Сообщение нового участника %s удалено",
+	"spam.usage":        "Использование: /spam [on|off|new <сообщений> <часов>|block link|invite|forward|media...|action delete|warn|mute|ban...|mute <длительность>]",
+
+	"spamlog.empty": "Спам-фильтр ничего не блокировал",
+	"spamlog.entry": "#%d %s %s: %s «%s» (действия: %s)",
+	"spamlog.title": "Заблокировано спам-фильтром:",
+	"spamlog.usage": "Использование: /spamlog [количество записей]",
+
+	"start.hello": "Привет,",
+
+	"target.error":     "Произошла неизвестная ошибка при поиске пользователя: %s",
+	"target.many":      "Найдено более одного пользователя, уточните:\n%s",
+	"target.not_found": "Пользователь %s не найден",
+	"target.self":      "Со мной так нельзя!",
+	"target.usage":     "Укажите пользователя: ответьте на его сообщение, упомяните его или укажите @username, имя или ID",
+
+	"timezone.current": "Часовой пояс чата: %s",
+	"timezone.failed":  "Не удалось установить часовой пояс %s",
+
+	"unwarn.not_found": "Предупреждение #%d не найдено",
+	"unwarn.removed":   "Предупреждение #%d пользователя %s снято",
+	"unwarn.usage":     "Использование: /unwarn <номер предупреждения>",
+
+	"warn.issued": "Пользователь %s получил предупреждение #%d (%s)",
+	"warn.self":   "Сам себя? O_o",
+
+	"warns.empty":  "У пользователя %s нет предупреждений",
+	"warns.expire": " (до %s)",
+	"warns.issuer": " от %s",
+	"warns.title":  "Предупреждения пользователя %s:",
+
+	"warnset.description": "Срок действия предупреждения: %s, санкции: %s",
+	"warnset.forever":     "бессрочно",
+	"warnset.usage":       "Использование: /warnset [expire <дней>], санкции настраиваются командой /policy warn",
+
+	"web.admin_hint":           "Администраторы архива видят сообщения, удаленные модерацией. Пустой токен завершает сеанс.",
+	"web.admin_login":          "Войти",
+	"web.admin_token":          "Токен администратора",
+	"web.audio":                "Аудио в сообщении",
+	"web.calendar":             "Календарь",
+	"web.chat_not_found":       "Чат не найден",
+	"web.chats":                "Чаты",
+	"web.document":             "Документ в сообщении",
+	"web.feed_description":     "Логи Telegram чата %s",
+	"web.first_page":           "Первая страница",
+	"web.latest":               "Последняя активность",
+	"web.message":              "Сообщение",
+	"web.messages":             "Сообщения",
+	"web.messages_unavailable": "Сообщения недоступны",
+	"web.month":                "%s %d",
+	"web.months":               "Месяцы",
+	"web.next_page":            "Следующая страница",
+	"web.photo":                "Фото в сообщении",
+	"web.removed":              "Удалено модерацией: %s",
+	"web.reply_hidden":         "Сообщение удалено модерацией",
+	"web.reset":                "сбросить",
+	"web.settings_unavailable": "Настройки чата недоступны",
+	"web.sticker":              "Стикер в сообщении",
+	"web.timezone":             "Часовой пояс: %s",
+	"web.title":                "Логи Telegram",
+	"web.today":                "Сегодня",
+	"web.video":                "Видео в сообщении",
+	"web.voice":                "Голосовое сообщение",
+	"web.wrong_month":          "Неверный месяц %d",
+	"web.years":                "Годы",
+
+	"weekday.0": "Вс",
+	"weekday.1": "Пн",
+	"weekday.2": "Вт",
+	"weekday.3": "Ср",
+	"weekday.4": "Чт",
+	"weekday.5": "Пт",
+	"weekday.6": "Сб",
+}
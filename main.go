@@ -5,6 +5,8 @@ import (
 	"log"
 
 	"github.com/elemc/gotelegrambot/db"
+	"github.com/elemc/gotelegrambot/escalation"
+	"github.com/elemc/gotelegrambot/flood"
 	"github.com/elemc/gotelegrambot/httpserver"
 
 	"gopkg.in/telegram-bot-api.v4"
@@ -20,15 +22,33 @@ func init() {
 
 	flag.StringVar(&settings.APIKey, "api-key", settings.APIKey, "API key for Telegram bot")
 	flag.StringVar(&settings.Addr, "addr", settings.Addr, "address string host:port for listen http server")
+	flag.StringVar(&settings.BaseURL, "base-url", settings.BaseURL, "public URL of http server for absolute links in feeds")
 	flag.StringVar(&settings.Couchbase.Cluster, "couch-cluster", settings.Couchbase.Cluster, "url to couchbase cluster")
 	flag.StringVar(&settings.Couchbase.Bucket, "couch-bucket", settings.Couchbase.Bucket, "couchbase bucket name")
 	flag.StringVar(&settings.Couchbase.Secret, "couch-secret", settings.Couchbase.Secret, "couchbase bucket password")
 	flag.StringVar(&settings.StaticDirPath, "static-dir-path", "static", "set path to static dir")
+	flag.StringVar(&settings.Timezone, "timezone", settings.Timezone, "default timezone for chats, e.g. Europe/Moscow")
+	flag.StringVar(&settings.AdminToken, "admin-token", settings.AdminToken, "token of web archive admins, they see messages removed by moderation")
+	flag.BoolVar(&settings.EditCommands, "edit-commands", settings.EditCommands, "handle commands added or changed by message edit")
 }
 
 func main() {
 	flag.Parse()
 	//SaveConfig()
+	if err := db.SetDefaultLocation(settings.Timezone); err != nil {
+		log.Fatalf("Cannot load timezone %s: %s", settings.Timezone, err)
+	}
+	warnPolicy, err := escalation.ParsePolicy(settings.Escalation.Warn)
+	if err != nil {
+		log.Fatalf("Cannot parse warn escalation policy: %s", err)
+	}
+	censPolicy, err := escalation.ParsePolicy(settings.Escalation.Cens)
+	if err != nil {
+		log.Fatalf("Cannot parse cens escalation policy: %s", err)
+	}
+	if err = db.SetDefaultPolicies(warnPolicy, censPolicy); err != nil {
+		log.Fatalf("Cannot set escalation policies: %s", err)
+	}
 	db.InitCouchbase(settings.Couchbase.Cluster, settings.Couchbase.Bucket, settings.Couchbase.Secret)
 
 	bot, err := tgbotapi.NewBotAPI(settings.APIKey)
@@ -41,12 +61,15 @@ func main() {
 	log.Printf("Authorized on account %s", bot.Self.UserName)
 
 	// start http server
-	s := httpserver.Server{Addr: settings.Addr, Bot: bot}
-	s.PhotoCache = make(httpserver.PhotosCache)
-	s.FileCache = make(httpserver.FilesCache)
+	s := httpserver.Server{Addr: settings.Addr, BaseURL: settings.BaseURL, Bot: bot}
 	s.APIKey = settings.APIKey
 	s.StaticDirPath = settings.StaticDirPath
+	s.AdminToken = settings.AdminToken
+	s.FloodLimiter = flood.NewLimiter(flood.SystemClock)
+	s.InitCommands()
+	s.InitCallbacks()
 	go s.FillCens()
+	go s.RunScheduler()
 	go s.Start()
 	//s.Start()
 
@@ -59,6 +82,19 @@ func main() {
 	}
 
 	for update := range updates {
+		if update.EditedMessage != nil {
+			go func(msg *tgbotapi.Message) {
+				if settings.EditCommands {
+					s.EditedCommandHandler(msg)
+				}
+				db.GoSaveEditedMessage(msg)
+			}(update.EditedMessage)
+			continue
+		}
+		if update.CallbackQuery != nil {
+			go s.CallbackQueryHandler(update.CallbackQuery)
+			continue
+		}
 		if update.Message == nil {
 			continue
 		}
@@ -67,7 +103,7 @@ func main() {
 
 		// Photo
 		id := int64(update.Message.From.ID)
-		if _, ok := s.PhotoCache[id]; !ok {
+		if _, ok := s.PhotoCache.Get(id); !ok {
 			go s.GetPhoto(id)
 		}
 
@@ -93,13 +129,19 @@ func main() {
 			go s.GetFile(update.Message.Voice.FileID, update.Message.Chat.ID)
 		}
 
+		// Joined and left members
+		go s.MembersHandler(update.Message)
+
+		// Flood and spam, they are enabled by chat settings
+		go s.CheckFlood(update.Message)
+		go s.CheckSpam(update.Message)
+
 		// Commands
 		if update.Message.IsCommand() {
 			go s.CommandHandler(update.Message)
 		} else {
-			// Cens
-			// Disable cens
-			//go s.Cens(update.Message)
+			// Cens, it is enabled by chat settings
+			go s.Cens(update.Message)
 		}
 	}
 }